@@ -26,6 +26,7 @@ import (
 	"k8s.io/client-go/rest"
 
 	"fossil.or.id/ghost-operator/pkg/apis"
+	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
 	"fossil.or.id/ghost-operator/pkg/controller"
 	"fossil.or.id/ghost-operator/version"
 
@@ -51,6 +52,12 @@ var (
 	metricsPort         int32 = 8383
 	operatorMetricsPort int32 = 8686
 )
+
+// Change below variables to serve admission webhooks on different port or with certificate from different directory.
+var (
+	webhookPort    = 9443
+	webhookCertDir = "/tmp/k8s-webhook-server/serving-certs"
+)
 var log = logf.Log.WithName("cmd")
 
 func printVersion() {
@@ -69,6 +76,10 @@ func main() {
 	// controller-runtime)
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 
+	// Admission webhooks need a serving certificate, so they are only served when explicitly enabled.
+	enableWebhooks := pflag.Bool("enable-webhooks", false, "Serve GhostApp admission webhooks. "+
+		"Requires serving certificate in "+webhookCertDir)
+
 	pflag.Parse()
 
 	// Use a zap logr.Logger implementation. If none of the zap
@@ -109,6 +120,8 @@ func main() {
 		Namespace:          namespace,
 		MapperProvider:     restmapper.NewDynamicRESTMapper,
 		MetricsBindAddress: fmt.Sprintf("%s:%d", metricsHost, metricsPort),
+		Port:               webhookPort,
+		CertDir:            webhookCertDir,
 	})
 	if err != nil {
 		log.Error(err, "")
@@ -129,6 +142,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Setup all Webhooks
+	if *enableWebhooks {
+		if err := (&ghostv1alpha1.GhostApp{}).SetupWebhookWithManager(mgr); err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+	}
+
 	if err = serveCRMetrics(cfg); err != nil {
 		log.Info("Could not generate and serve custom resource metrics", "error", err.Error())
 	}
@@ -15,107 +15,368 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
-// GhostDatabaseConnectionSpec defines ghost database connection.
-type GhostDatabaseConnectionSpec struct {
-	// sqlite filename.
+// GhostPersistentSpec defines peristent volume
+type GhostPersistentSpec struct {
+	Enabled bool `json:"enabled"`
+	// If defined, will create persistentVolumeClaim with spesific storageClass name.
+	// If undefined (the default) or set to null, no storageClassName spec is set, choosing the default provisioner.
+	// +nullable
+	StorageClass *string `json:"storageClass,omitempty"`
+	// size of storage, defaults to 10Gi.
 	// +optional
-	Filename string `json:"filename,omitempty"`
-	// mysql host
+	Size resource.Quantity `json:"size,omitempty"`
+	// VolumeSnapshotClassName used by Snapshot deletion policy.
+	// If undefined, the default VolumeSnapshotClass of the cluster is used.
 	// +optional
-	Host string `json:"host,omitempty"`
-	// mysql port
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+	// DeleteWhenDisabled deletes persistentVolumeClaim, along with ghost content stored in it, when persistent is
+	// disabled. Otherwise the persistentVolumeClaim is retained and used again once persistent is enabled.
 	// +optional
-	Port intstr.IntOrString `json:"port,omitempty"`
-	// mysql database user
+	DeleteWhenDisabled bool `json:"deleteWhenDisabled,omitempty"`
+}
+
+// GhostIngressTLSSpec defines ingress tls
+type GhostIngressTLSSpec struct {
+	Enabled bool `json:"enabled"`
+	// SecretName of certificate serving every ingress host when certificates is empty.
+	// Ingress controller default certificate is used when not defined.
 	// +optional
-	User string `json:"user,omitempty"`
-	// mysql database password of user
+	SecretName string `json:"secretName,omitempty"`
+	// Certificates serving different ingress hosts, eg: an apex domain and a legacy domain.
 	// +optional
-	Password string `json:"password,omitempty"`
-	// mysql database name
+	Certificates []GhostIngressTLSCertificateSpec `json:"certificates,omitempty"`
+	// IssuerRef makes the operator request a cert-manager Certificate for every ingress host,
+	// stored in secretName, or "<name>-tls" when secretName is not defined.
 	// +optional
-	Database string `json:"database,omitempty"`
-}
-
-type GhostServerSpec struct {
-	Host string             `json:"host"`
-	Port intstr.IntOrString `json:"port"`
+	IssuerRef *GhostCertificateIssuerReference `json:"issuerRef,omitempty"`
 }
 
-// GhostDatabaseSpec defines ghost database config.
-// https://ghost.org/docs/concepts/config/#database
-type GhostDatabaseSpec struct {
-	// Client is ghost database client.
-	// +kubebuilder:validation:Enum=sqlite3;mysql
-	Client string `json:"client"`
+// GhostCertificateIssuerReference references cert-manager issuer of ingress certificate
+type GhostCertificateIssuerReference struct {
+	// Name of the issuer.
+	Name string `json:"name"`
+	// Kind of the issuer. Defaults to Issuer.
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// Group of the issuer. Defaults to cert-manager.io.
 	// +optional
-	Connection GhostDatabaseConnectionSpec `json:"connection"`
+	Group string `json:"group,omitempty"`
 }
 
-// GhostConfigSpec defines related ghost configuration based on https://ghost.org/docs/concepts/config
-// TODO (prksu): we need support all ghost configuration since we reference this spec as ghost config too.
-// TODO (prksu): move ghost config to another file.
-type GhostConfigSpec struct {
-	URL string `json:"url"`
-
-	Database GhostDatabaseSpec `json:"database"`
+// GhostIngressTLSCertificateSpec defines certificate serving some of ingress hosts
+type GhostIngressTLSCertificateSpec struct {
+	// Hosts served by this certificate. Every host must be one of ingress hosts.
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	Hosts []string `json:"hosts"`
+	// SecretName of this certificate. Ingress controller default certificate is used when not defined.
 	// +optional
-	Server GhostServerSpec `json:"server"`
+	SecretName string `json:"secretName,omitempty"`
 }
 
-// GhostPersistentSpec defines peristent volume
-type GhostPersistentSpec struct {
-	Enabled bool `json:"enabled"`
-	// If defined, will create persistentVolumeClaim with spesific storageClass name.
-	// If undefined (the default) or set to null, no storageClassName spec is set, choosing the default provisioner.
-	// +nullable
-	StorageClass *string `json:"storageClass,omitempty"`
-	// size of storage
-	Size resource.Quantity `json:"size"`
-}
+// GhostIngressPathType is how ingress path is matched against request path
+// +kubebuilder:validation:Enum=Exact;Prefix;ImplementationSpecific
+type GhostIngressPathType string
 
-// GhostIngressTLSSpec defines ingress tls
-type GhostIngressTLSSpec struct {
-	Enabled    bool   `json:"enabled"`
-	SecretName string `json:"secretName"`
-}
+const (
+	// GhostIngressPathTypeExact matches the request path exactly.
+	GhostIngressPathTypeExact GhostIngressPathType = "Exact"
+	// GhostIngressPathTypePrefix matches the request path by path elements prefix.
+	GhostIngressPathTypePrefix GhostIngressPathType = "Prefix"
+	// GhostIngressPathTypeImplementationSpecific leaves path matching to the ingress controller.
+	GhostIngressPathTypeImplementationSpecific GhostIngressPathType = "ImplementationSpecific"
+)
 
 // GhostIngressSpec defines ingress
 type GhostIngressSpec struct {
 	Enabled bool `json:"enabled"`
+	// Hosts routed to ghost. Defaults to host of config.url.
 	// +optional
 	// +listType=set
 	Hosts []string `json:"hosts,omitempty"`
 	// +optional
 	TLS GhostIngressTLSSpec `json:"tls,omitempty"`
+	// ClassName is the name of IngressClass handling ghost ingress. It is set as
+	// kubernetes.io/ingress.class annotation on clusters without networking.k8s.io/v1 Ingress.
+	// +optional
+	ClassName *string `json:"className,omitempty"`
+	// Path routed to ghost service. Defaults to path of config.url, so ghost can run under a subdirectory.
+	// +optional
+	Path string `json:"path,omitempty"`
+	// PathType of ingress path. Defaults to Prefix.
+	// +optional
+	PathType GhostIngressPathType `json:"pathType,omitempty"`
 	// Additional annotations passed to ".metadata.annotations" in networking.k8s.io/ingress object.
 	// This is useful for configuring ingress through annotation field like: ingress-class, static-ip, etc
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
+// GhostAppAdminSpec defines a separate ingress serving ghost admin panel on its own host,
+// eg: an internal hostname restricted by ip allowlisting annotations.
+type GhostAppAdminSpec struct {
+	Enabled bool `json:"enabled"`
+	// Host serving ghost admin panel. Written as admin.url in ghost configuration unless config.admin.url is defined.
+	// +optional
+	Host string `json:"host,omitempty"`
+	// +optional
+	TLS GhostAdminTLSSpec `json:"tls,omitempty"`
+	// ClassName is the name of IngressClass handling admin ingress. Defaults to className of ingress.
+	// +optional
+	ClassName *string `json:"className,omitempty"`
+	// Additional annotations passed to ".metadata.annotations" of admin ingress,
+	// eg: nginx.ingress.kubernetes.io/whitelist-source-range
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// GhostAdminTLSSpec defines admin ingress tls
+type GhostAdminTLSSpec struct {
+	Enabled bool `json:"enabled"`
+	// SecretName of certificate serving admin host. Ingress controller default certificate is used when not defined.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// GhostServiceSpec defines service exposing ghost pods
+type GhostServiceSpec struct {
+	// Type of ghost service. Defaults to NodePort when ingress is enabled, since some ingress controllers
+	// eg: gce require it, ClusterIP otherwise.
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+	// Port exposed by ghost service. Defaults to 2368.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+	// NodePort exposing ghost service on every node when type is NodePort or LoadBalancer.
+	// Allocated by the cluster when not defined.
+	// +optional
+	NodePort int32 `json:"nodePort,omitempty"`
+	// Additional annotations passed to ".metadata.annotations" in service object.
+	// This is useful for configuring cloud load balancers.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Additional labels passed to ".metadata.labels" in service object.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// LoadBalancerSourceRanges restricts client IPs allowed to access LoadBalancer service.
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+	// ExternalTrafficPolicy of NodePort or LoadBalancer service.
+	// +kubebuilder:validation:Enum=Cluster;Local
+	// +optional
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicyType `json:"externalTrafficPolicy,omitempty"`
+}
+
+// GhostGatewaySpec defines Gateway API HTTPRoute routing ingress hosts and path to ghost service
+type GhostGatewaySpec struct {
+	Enabled bool `json:"enabled"`
+	// ParentRef is the Gateway the HTTPRoute attaches to.
+	// +optional
+	ParentRef GhostGatewayParentReference `json:"parentRef,omitempty"`
+}
+
+// GhostGatewayParentReference references a Gateway
+type GhostGatewayParentReference struct {
+	// Name of the Gateway.
+	Name string `json:"name"`
+	// Namespace of the Gateway. Defaults to GhostApp namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// SectionName is the name of Gateway listener the HTTPRoute attaches to. All listeners are used when not defined.
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// GhostRouteTLSTermination is where OpenShift router terminates tls
+// +kubebuilder:validation:Enum=edge;reencrypt
+type GhostRouteTLSTermination string
+
+const (
+	// GhostRouteTLSTerminationEdge terminates tls at the router and sends plain http to ghost.
+	GhostRouteTLSTerminationEdge GhostRouteTLSTermination = "edge"
+	// GhostRouteTLSTerminationReencrypt terminates tls at the router and sends a new tls connection to ghost service,
+	// which must be served by ghost pods, eg: through a sidecar proxy.
+	GhostRouteTLSTerminationReencrypt GhostRouteTLSTermination = "reencrypt"
+)
+
+// GhostRouteSpec defines OpenShift Route created for every ingress host
+type GhostRouteSpec struct {
+	Enabled bool `json:"enabled"`
+	// +optional
+	TLS *GhostRouteTLSSpec `json:"tls,omitempty"`
+	// Additional annotations passed to ".metadata.annotations" in route.openshift.io/route objects.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// GhostRouteTLSSpec defines OpenShift Route tls
+type GhostRouteTLSSpec struct {
+	// Termination of tls. Defaults to edge.
+	// +optional
+	Termination GhostRouteTLSTermination `json:"termination,omitempty"`
+	// InsecureEdgeTerminationPolicy controls plain http requests. Defaults to Redirect.
+	// +kubebuilder:validation:Enum=Allow;Redirect;None
+	// +optional
+	InsecureEdgeTerminationPolicy string `json:"insecureEdgeTerminationPolicy,omitempty"`
+	// DestinationCACertificate validates certificate of ghost service when termination is reencrypt.
+	// +optional
+	DestinationCACertificate string `json:"destinationCACertificate,omitempty"`
+}
+
+// GhostPodTemplateSpec defines customization merged into the generated ghost pod.
+type GhostPodTemplateSpec struct {
+	// Additional labels added to ghost pods.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Additional annotations added to ghost pods.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Compute resources of ghost container.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// Additional environment variables of ghost container.
+	// Variables defined here take precedence over variables with the same name generated by this operator.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+	// Additional volumes of ghost pods. Names ghost-config and ghost-content are reserved.
+	// +optional
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+	// Additional volume mounts of ghost container.
+	// +optional
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+	// Init containers run before ghost container.
+	// +optional
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+	// Sidecars are containers run alongside ghost container. Name ghost is reserved.
+	// +optional
+	Sidecars []corev1.Container `json:"sidecars,omitempty"`
+}
+
+// GhostProbeSpec defines a probe of ghost container.
+type GhostProbeSpec struct {
+	// Disabled removes the probe from ghost container.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+	// Probe fields defined here override fields of the default probe.
+	// Defining a handler (httpGet, tcpSocket or exec) replaces the default http handler.
+	// +optional
+	corev1.Probe `json:",inline"`
+}
+
+// GhostProbesSpec defines probes of ghost container.
+// By default every probe sends http request to ghost with Host header of config.url.
+type GhostProbesSpec struct {
+	// Startup probe waits for ghost to finish database migrations on the first boot.
+	// +optional
+	Startup *GhostProbeSpec `json:"startup,omitempty"`
+	// Readiness probe requests ghost site root.
+	// +optional
+	Readiness *GhostProbeSpec `json:"readiness,omitempty"`
+	// Liveness probe requests ghost robots.txt.
+	// +optional
+	Liveness *GhostProbeSpec `json:"liveness,omitempty"`
+}
+
+// GhostSecuritySpec defines security settings of ghost pods. Ghost pods always run as non-root user without any
+// capabilities, privilege escalation and with the runtime default seccomp profile.
+type GhostSecuritySpec struct {
+	// RunAsUser is the uid ghost container runs as. Defaults to 1000, the node user of the official ghost image.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RunAsUser *int64 `json:"runAsUser,omitempty"`
+	// RunAsGroup is the gid ghost container runs as. Defaults to 1000.
+	// +optional
+	RunAsGroup *int64 `json:"runAsGroup,omitempty"`
+	// FSGroup owns ghost content volume, so ghost can write uploaded files. Defaults to 1000.
+	// +optional
+	FSGroup *int64 `json:"fsGroup,omitempty"`
+	// ReadOnlyRootFilesystem mounts the root filesystem of ghost container as read-only.
+	// Writable /tmp is mounted from an emptyDir volume.
+	// +optional
+	ReadOnlyRootFilesystem bool `json:"readOnlyRootFilesystem,omitempty"`
+}
+
+// GhostAppDeletionPolicy controls what happens to ghost content volume when GhostApp is deleted
+type GhostAppDeletionPolicy string
+
+const (
+	// GhostAppDeletionPolicyDelete deletes ghost content volume along with GhostApp
+	GhostAppDeletionPolicyDelete GhostAppDeletionPolicy = "Delete"
+
+	// GhostAppDeletionPolicyRetain keeps ghost content volume, it is adopted again by GhostApp with the same name
+	GhostAppDeletionPolicyRetain GhostAppDeletionPolicy = "Retain"
+
+	// GhostAppDeletionPolicySnapshot takes a VolumeSnapshot of ghost content volume before it is deleted
+	GhostAppDeletionPolicySnapshot GhostAppDeletionPolicy = "Snapshot"
+)
+
 // GhostAppSpec defines the desired state of GhostApp
 // +k8s:openapi-gen=true
 type GhostAppSpec struct {
-	// Ghost deployment repicas
+	// Ghost deployment repicas, defaults to 1.
 	// +optional
 	Replicas *int32 `json:"replicas,omitempty"`
 	// Ghost container image, by default using latest ghost image from docker hub registry.
 	// NOTE: This operator only support ghost image from docker official image. https://hub.docker.com/_/ghost/
 	// +optional
 	Image string `json:"image,omitempty"`
-	// Ghost configuration. This field will be written as ghost configuration. Saved in configmap and mounted
-	// in /etc/ghost/config/config.json and symlinked to /var/lib/ghost/config.production.json
+	// Ghost configuration. This field will be written as ghost configuration. Saved in secret and mounted
+	// in /var/lib/ghost/config.production.json
 	Config GhostConfigSpec `json:"config"`
 	// +optional
 	Persistent GhostPersistentSpec `json:"persistent,omitempty"`
+	// Probes overrides or disables default probes of ghost container.
+	// +optional
+	Probes GhostProbesSpec `json:"probes,omitempty"`
+	// Security defines security settings of ghost pods.
+	// +optional
+	Security GhostSecuritySpec `json:"security,omitempty"`
+	// PodTemplate customizes ghost pods generated by this operator.
+	// +optional
+	PodTemplate GhostPodTemplateSpec `json:"podTemplate,omitempty"`
+	// DeletionPolicy controls what happens to persistent ghost content, including sqlite database,
+	// when GhostApp is deleted. One of Delete, Retain, Snapshot, defaults to Delete.
+	// +kubebuilder:validation:Enum=Delete;Retain;Snapshot
+	// +optional
+	DeletionPolicy GhostAppDeletionPolicy `json:"deletionPolicy,omitempty"`
+	// +optional
+	Service GhostServiceSpec `json:"service,omitempty"`
 	// +optional
 	Ingress GhostIngressSpec `json:"ingress,omitempty"`
+	// Gateway exposes ghost through a Gateway API HTTPRoute, as an alternative to ingress.
+	// +optional
+	Gateway GhostGatewaySpec `json:"gateway,omitempty"`
+	// Route exposes ghost through OpenShift Routes, as an alternative to ingress.
+	// +optional
+	Route GhostRouteSpec `json:"route,omitempty"`
+	// Admin serves ghost admin panel through a separate ingress on its own host.
+	// +optional
+	Admin GhostAppAdminSpec `json:"admin,omitempty"`
 }
 
 // GhostAppPhaseType represents the current phase of GhostApp instances
@@ -140,14 +401,158 @@ const (
 	GhostAppPhaseFailure GhostAppPhaseType = "Failure"
 )
 
+// GhostAppConditionType represents a condition type of GhostApp
+// +k8s:openapi-gen=true
+type GhostAppConditionType string
+
+const (
+	// GhostAppConditionConfigReady indicates that ghost configuration is rendered
+	GhostAppConditionConfigReady GhostAppConditionType = "ConfigReady"
+
+	// GhostAppConditionStorageReady indicates that ghost content volume is ready to be used
+	GhostAppConditionStorageReady GhostAppConditionType = "StorageReady"
+
+	// GhostAppConditionDeploymentAvailable indicates that ghost deployment has minimum availability
+	GhostAppConditionDeploymentAvailable GhostAppConditionType = "DeploymentAvailable"
+
+	// GhostAppConditionServiceReady indicates that ghost service has ready endpoints
+	GhostAppConditionServiceReady GhostAppConditionType = "ServiceReady"
+
+	// GhostAppConditionIngressReady indicates that ghost ingress is admitted by ingress controller
+	GhostAppConditionIngressReady GhostAppConditionType = "IngressReady"
+
+	// GhostAppConditionAdminIngressReady indicates that ghost admin ingress is admitted by ingress controller
+	GhostAppConditionAdminIngressReady GhostAppConditionType = "AdminIngressReady"
+
+	// GhostAppConditionHTTPRouteAccepted indicates that ghost HTTPRoute is accepted by its parent gateway
+	GhostAppConditionHTTPRouteAccepted GhostAppConditionType = "HTTPRouteAccepted"
+
+	// GhostAppConditionRouteAdmitted indicates that every ghost OpenShift Route is admitted by a router
+	GhostAppConditionRouteAdmitted GhostAppConditionType = "RouteAdmitted"
+
+	// GhostAppConditionURLConsistent indicates that config.url matches scheme, host and path of ghost ingress.
+	// It does not affect Ready condition, since ghost may be exposed by other means than ingress.
+	GhostAppConditionURLConsistent GhostAppConditionType = "URLConsistent"
+
+	// GhostAppConditionReady indicates that all other conditions of GhostApp are true
+	GhostAppConditionReady GhostAppConditionType = "Ready"
+)
+
+// GhostAppCondition describes the state of GhostApp at a certain point.
+// +k8s:openapi-gen=true
+type GhostAppCondition struct {
+	// Type of GhostApp condition.
+	Type GhostAppConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// ObservedGeneration is the GhostApp generation the condition was set based upon.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+	// Reason is a brief CamelCase reason for the condition's last transition.
+	Reason string `json:"reason"`
+	// Message is a human readable message indicating details about the transition.
+	Message string `json:"message"`
+}
+
+// GhostAppPodStatus describes a problem of a ghost pod container.
+// +k8s:openapi-gen=true
+type GhostAppPodStatus struct {
+	// Name of the pod.
+	Name string `json:"name"`
+	// Container is the name of the failing container, empty when the pod itself can not be scheduled.
+	// +optional
+	Container string `json:"container,omitempty"`
+	// Reason is a brief CamelCase reason of the problem, eg: ImagePullBackOff, CrashLoopBackOff, OOMKilled.
+	Reason string `json:"reason"`
+	// Message is a human readable message indicating details about the problem.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// RestartCount is the number of times the container has been restarted.
+	// +optional
+	RestartCount int32 `json:"restartCount,omitempty"`
+	// TerminationMessage is the message the container wrote to /dev/termination-log before it last terminated.
+	// +optional
+	TerminationMessage string `json:"terminationMessage,omitempty"`
+}
+
+// GhostAppCertificateStatus reports cert-manager Certificate issued for ingress hosts
+type GhostAppCertificateStatus struct {
+	// Name of the Certificate.
+	Name string `json:"name"`
+	// Ready is true when the certificate is issued and valid.
+	Ready bool `json:"ready"`
+	// NotAfter is the expiry time of the issued certificate.
+	// +optional
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+	// RenewalTime is the time cert-manager renews the certificate.
+	// +optional
+	RenewalTime *metav1.Time `json:"renewalTime,omitempty"`
+	// Reason of the certificate Ready condition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message of the certificate Ready condition, eg: why issuing the certificate failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// GhostAppRouteStatus reports OpenShift Route of ghost
+type GhostAppRouteStatus struct {
+	// Name of the Route.
+	Name string `json:"name"`
+	// Host of the Route, either requested or generated by the router.
+	// +optional
+	Host string `json:"host,omitempty"`
+	// Admitted is true when a router admitted the Route.
+	Admitted bool `json:"admitted"`
+}
+
 // GhostAppStatus defines the observed state of GhostApp
 // +k8s:openapi-gen=true
 type GhostAppStatus struct {
+	// Replicas is the number of ghost pods targeted by ghost deployment.
 	Replicas int32 `json:"replicas,omitempty"`
+	// ReadyReplicas is the number of ghost pods ready to serve requests.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
 	// Represents the latest available observations of a ghostapp current state.
 	Phase GhostAppPhaseType `json:"phase,omitempty"`
+	// ObservedGeneration is the most recent GhostApp generation observed by the operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions of GhostApp.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []GhostAppCondition `json:"conditions,omitempty"`
+	// UnhealthyPods lists ghost pod containers that are failing to run.
+	// +optional
+	UnhealthyPods []GhostAppPodStatus `json:"unhealthyPods,omitempty"`
 
 	Reason string `json:"reason,omitempty"`
+	// Routes reports OpenShift Routes of ghost.
+	// +optional
+	Routes []GhostAppRouteStatus `json:"routes,omitempty"`
+	// Certificate reports cert-manager Certificate issued for ingress hosts.
+	// +optional
+	Certificate *GhostAppCertificateStatus `json:"certificate,omitempty"`
+	// ConfigHash is the hash of the ghost configuration applied to the ghost pods.
+	// +optional
+	ConfigHash string `json:"configHash,omitempty"`
+	// AcceptedSpec records spec fields of the last reconciled GhostApp that can not be changed safely.
+	// +optional
+	AcceptedSpec *GhostAppAcceptedSpec `json:"acceptedSpec,omitempty"`
+}
+
+// GhostAppAcceptedSpec defines spec fields the operator validates updates of, so unsafe changes are rejected
+// even when the validating webhook is not enabled.
+type GhostAppAcceptedSpec struct {
+	// DatabaseClient is the database client ghost was reconciled with.
+	DatabaseClient string `json:"databaseClient"`
+	// Persistent is the ghost content volume ghost was reconciled with.
+	// +optional
+	Persistent *GhostPersistentSpec `json:"persistent,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -158,6 +563,7 @@ type GhostAppStatus struct {
 // +kubebuilder:resource:path=ghostapps,scope=Namespaced
 // +kubebuilder:printcolumn:name="replicas",type="string",JSONPath=".status.replicas"
 // +kubebuilder:printcolumn:name="phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
 // +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
 type GhostApp struct {
 	metav1.TypeMeta   `json:",inline"`
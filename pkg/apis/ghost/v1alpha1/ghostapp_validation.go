@@ -0,0 +1,334 @@
+// Copyright 2020 Fossil Dev
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Validate returns an Invalid error when r spec can not be reconciled.
+func (r *GhostApp) Validate() error {
+	if errs := r.validate(); len(errs) > 0 {
+		return apierrors.NewInvalid(SchemeGroupVersion.WithKind("GhostApp").GroupKind(), r.GetName(), errs)
+	}
+
+	return nil
+}
+
+// ValidateTransition returns an Invalid error when r spec can not be reconciled
+// or when it changes fields of old that can not be changed safely.
+// Updates that leave the spec unchanged, eg: removing the finalizer, and updates of GhostApp being deleted
+// are always allowed, so GhostApp admitted before a stricter rule can still be deleted.
+func (r *GhostApp) ValidateTransition(old *GhostApp) error {
+	if r.GetDeletionTimestamp() != nil || equality.Semantic.DeepEqual(old.Spec, r.Spec) {
+		return nil
+	}
+
+	allErrs := r.validate()
+	allErrs = append(allErrs, r.validateTransition(old)...)
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(SchemeGroupVersion.WithKind("GhostApp").GroupKind(), r.GetName(), allErrs)
+	}
+
+	return nil
+}
+
+// ValidateAcceptedTransition returns an Invalid error when r spec changes fields recorded in its status
+// by the last reconcile that can not be changed safely. It is validated by the operator, since updates are only
+// validated by the validating webhook when webhooks are enabled.
+func (r *GhostApp) ValidateAcceptedTransition() error {
+	accepted := r.Status.AcceptedSpec
+	if accepted == nil {
+		return nil
+	}
+
+	old := &GhostApp{}
+	old.Spec.Config.Database.Client = accepted.DatabaseClient
+	if accepted.Persistent != nil {
+		old.Spec.Persistent = *accepted.Persistent
+	}
+	if allErrs := r.validateTransition(old); len(allErrs) > 0 {
+		return apierrors.NewInvalid(SchemeGroupVersion.WithKind("GhostApp").GroupKind(), r.GetName(), allErrs)
+	}
+
+	return nil
+}
+
+// AcceptSpec records fields of r spec validated by ValidateAcceptedTransition in its status.
+func (r *GhostApp) AcceptSpec() {
+	accepted := &GhostAppAcceptedSpec{DatabaseClient: r.Spec.Config.Database.Client}
+	if r.Spec.Persistent.Enabled {
+		accepted.Persistent = r.Spec.Persistent.DeepCopy()
+	}
+	r.Status.AcceptedSpec = accepted
+}
+
+func (r *GhostApp) validate() field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	configPath := specPath.Child("config")
+
+	allErrs = append(allErrs, validateURL(r.Spec.Config.URL, configPath.Child("url"))...)
+
+	database := r.Spec.Config.Database
+	switch database.Client {
+	case "sqlite3":
+		// sqlite database file can not be shared between ghost processes.
+		if r.Spec.Replicas != nil && *r.Spec.Replicas > 1 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("replicas"), *r.Spec.Replicas, "must be 1 when using sqlite3 database client"))
+		}
+	case "mysql":
+		if database.Connection.Host == "" {
+			allErrs = append(allErrs, field.Required(configPath.Child("database", "connection", "host"), "required when using mysql database client"))
+		}
+	}
+
+	if r.Spec.Ingress.Enabled && r.Spec.Ingress.TLS.Enabled && len(r.IngressHosts()) == 0 {
+		allErrs = append(allErrs, field.Required(specPath.Child("ingress", "hosts"), "required when ingress tls is enabled and config.url has no domain name"))
+	}
+
+	allErrs = append(allErrs, validateIngressTLS(r, specPath.Child("ingress"))...)
+
+	if path := r.Spec.Ingress.Path; path != "" && !strings.HasPrefix(path, "/") {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("ingress", "path"), path, "must be an absolute path"))
+	}
+
+	if r.Spec.Gateway.Enabled && r.Spec.Gateway.ParentRef.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("gateway", "parentRef", "name"), "required when gateway is enabled"))
+	}
+
+	if tls := r.Spec.Route.TLS; tls != nil && tls.DestinationCACertificate != "" && tls.Termination != GhostRouteTLSTerminationReencrypt {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("route", "tls", "destinationCACertificate"), "may only be used when termination is reencrypt"))
+	}
+
+	allErrs = append(allErrs, validateAdmin(r, specPath.Child("admin"))...)
+	allErrs = append(allErrs, validateService(r, specPath.Child("service"))...)
+
+	if runAsUser := r.Spec.Security.RunAsUser; runAsUser != nil && *runAsUser == 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("security", "runAsUser"), *runAsUser, "ghost must not run as root"))
+	}
+
+	allErrs = append(allErrs, validateMail(r.Spec.Config.Mail, configPath.Child("mail"))...)
+	allErrs = append(allErrs, validatePodTemplate(r, specPath.Child("podTemplate"))...)
+	return allErrs
+}
+
+func (r *GhostApp) validateTransition(old *GhostApp) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if r.Spec.Config.Database.Client != old.Spec.Config.Database.Client {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("config", "database", "client"), "database client is immutable"))
+	}
+
+	if r.Spec.Persistent.Enabled && old.Spec.Persistent.Enabled {
+		persistentPath := specPath.Child("persistent")
+		if !reflect.DeepEqual(r.Spec.Persistent.StorageClass, old.Spec.Persistent.StorageClass) {
+			allErrs = append(allErrs, field.Forbidden(persistentPath.Child("storageClass"), "storage class is immutable"))
+		}
+		if r.Spec.Persistent.Size.Cmp(old.Spec.Persistent.Size) < 0 {
+			allErrs = append(allErrs, field.Forbidden(persistentPath.Child("size"), "size can not be decreased"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateURL(rawurl string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	u, err := url.Parse(rawurl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath, rawurl, "must be an absolute http or https URL"))
+	}
+
+	return allErrs
+}
+
+func validateIngressTLS(r *GhostApp, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	spec := r.Spec.Ingress
+	certificatesPath := fldPath.Child("tls", "certificates")
+	if len(spec.TLS.Certificates) > 0 && !spec.TLS.Enabled {
+		allErrs = append(allErrs, field.Forbidden(certificatesPath, "may only be used when ingress tls is enabled"))
+	}
+
+	if spec.TLS.IssuerRef != nil {
+		issuerRefPath := fldPath.Child("tls", "issuerRef")
+		if !spec.TLS.Enabled {
+			allErrs = append(allErrs, field.Forbidden(issuerRefPath, "may only be used when ingress tls is enabled"))
+		}
+		if len(spec.TLS.Certificates) > 0 {
+			allErrs = append(allErrs, field.Forbidden(issuerRefPath, "may not be used together with certificates"))
+		}
+		if spec.TLS.IssuerRef.Name == "" {
+			allErrs = append(allErrs, field.Required(issuerRefPath.Child("name"), ""))
+		}
+	}
+
+	for i, certificate := range spec.TLS.Certificates {
+		if len(certificate.Hosts) == 0 {
+			allErrs = append(allErrs, field.Required(certificatesPath.Index(i).Child("hosts"), ""))
+		}
+		for j, host := range certificate.Hosts {
+			if !containsString(r.IngressHosts(), host) {
+				allErrs = append(allErrs, field.Invalid(certificatesPath.Index(i).Child("hosts").Index(j), host, "must be one of ingress hosts"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func validateAdmin(r *GhostApp, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	spec := r.Spec.Admin
+	if !spec.Enabled {
+		return allErrs
+	}
+
+	hostPath := fldPath.Child("host")
+	switch {
+	case spec.Host == "":
+		allErrs = append(allErrs, field.Required(hostPath, "required when admin is enabled"))
+	case strings.Contains(spec.Host, "/") || strings.Contains(spec.Host, ":"):
+		allErrs = append(allErrs, field.Invalid(hostPath, spec.Host, "must be a hostname without scheme, port or path"))
+	case r.IsIngressEnabled() && containsString(r.IngressHosts(), spec.Host):
+		allErrs = append(allErrs, field.Invalid(hostPath, spec.Host, "must not be one of ingress hosts"))
+	}
+
+	// admin ingress only routes admin host, admin panel is unreachable on any other admin.url.
+	if config := r.Spec.Config.Admin; config != nil && config.URL != "" && spec.Host != "" {
+		if u, err := url.Parse(config.URL); err == nil && u.Hostname() != spec.Host {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "config", "admin", "url"), config.URL, "host must match admin host"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateService(r *GhostApp, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	spec := r.Spec.Service
+	serviceType := r.ServiceType()
+
+	if spec.NodePort != 0 && serviceType == corev1.ServiceTypeClusterIP {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("nodePort"), "may not be used when type is ClusterIP"))
+	}
+
+	if spec.ExternalTrafficPolicy != "" && serviceType == corev1.ServiceTypeClusterIP {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("externalTrafficPolicy"), "may not be used when type is ClusterIP"))
+	}
+
+	if len(spec.LoadBalancerSourceRanges) > 0 && serviceType != corev1.ServiceTypeLoadBalancer {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("loadBalancerSourceRanges"), "may only be used when type is LoadBalancer"))
+	}
+
+	for i, cidr := range spec.LoadBalancerSourceRanges {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("loadBalancerSourceRanges").Index(i), cidr, "must be a CIDR"))
+		}
+	}
+
+	return allErrs
+}
+
+// reservedVolumeNames are names of volumes generated in ghost pods.
+var reservedVolumeNames = []string{"ghost-config", "ghost-content", "ghost-tmp"}
+
+// reservedMountPaths are paths mounted by generated volumes in ghost container.
+var reservedMountPaths = []string{"/var/lib/ghost/config.production.json", "/var/lib/ghost/content"}
+
+// readOnlyRootFilesystemMountPath is writable path mounted in ghost container with read-only root filesystem.
+const readOnlyRootFilesystemMountPath = "/tmp"
+
+func validatePodTemplate(r *GhostApp, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	spec := r.Spec.PodTemplate
+	mountPaths := reservedMountPaths
+	if r.Spec.Security.ReadOnlyRootFilesystem {
+		mountPaths = append([]string{readOnlyRootFilesystemMountPath}, reservedMountPaths...)
+	}
+
+	for i, volume := range spec.Volumes {
+		if containsString(reservedVolumeNames, volume.Name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("volumes").Index(i).Child("name"), volume.Name, "name is reserved"))
+		}
+	}
+
+	for i, mount := range spec.VolumeMounts {
+		if containsString(mountPaths, mount.MountPath) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("volumeMounts").Index(i).Child("mountPath"), mount.MountPath, "path is mounted by ghost-operator"))
+		}
+	}
+
+	for i, container := range spec.Sidecars {
+		if container.Name == "ghost" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("sidecars").Index(i).Child("name"), container.Name, "name is reserved"))
+		}
+	}
+
+	return allErrs
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateMail(spec *GhostMailSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec == nil {
+		return allErrs
+	}
+
+	if spec.From != "" {
+		if _, err := mail.ParseAddress(spec.From); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("from"), spec.From, "must be an email address"))
+		}
+	}
+
+	if spec.Transport == "SMTP" {
+		optsPath := fldPath.Child("options")
+		if spec.Options == nil || (spec.Options.Service == "" && spec.Options.Host == "") {
+			allErrs = append(allErrs, field.Required(optsPath.Child("host"), "either host or service is required for SMTP transport"))
+		}
+	}
+
+	if spec.Options != nil && spec.Options.Auth != nil {
+		authPath := fldPath.Child("options", "auth")
+		if spec.Options.Auth.UserSecretRef == nil {
+			allErrs = append(allErrs, field.Required(authPath.Child("userSecretRef"), ""))
+		}
+		if spec.Options.Auth.PassSecretRef == nil {
+			allErrs = append(allErrs, field.Required(authPath.Child("passSecretRef"), ""))
+		}
+	}
+
+	return allErrs
+}
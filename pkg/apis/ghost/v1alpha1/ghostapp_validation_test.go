@@ -0,0 +1,522 @@
+// Copyright 2020 Fossil Dev
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestGhostApp() *GhostApp {
+	return &GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: GhostAppSpec{
+			Config: GhostConfigSpec{
+				URL: "http://example.ghostapp.test",
+				Database: GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+		},
+	}
+}
+
+// invalidFields returns paths of fields rejected by Invalid error err.
+func invalidFields(err error) []string {
+	status, ok := err.(apierrors.APIStatus)
+	if !ok || status.Status().Details == nil {
+		return nil
+	}
+
+	var fields []string
+	for _, cause := range status.Status().Details.Causes {
+		fields = append(fields, cause.Field)
+	}
+
+	return fields
+}
+
+func TestValidate(t *testing.T) {
+	secretRef := &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "test-ghostapp-mail"},
+		Key:                  "user",
+	}
+
+	tests := []struct {
+		name      string
+		mutate    func(r *GhostApp)
+		wantField string
+	}{
+		{
+			name:   "Test Valid GhostApp",
+			mutate: func(r *GhostApp) {},
+		},
+		{
+			name: "Test Invalid Config URL",
+			mutate: func(r *GhostApp) {
+				r.Spec.Config.URL = "example.ghostapp.test"
+			},
+			wantField: "spec.config.url",
+		},
+		{
+			name: "Test Sqlite3 With Multiple Replicas",
+			mutate: func(r *GhostApp) {
+				replicas := int32(3)
+				r.Spec.Replicas = &replicas
+			},
+			wantField: "spec.replicas",
+		},
+		{
+			name: "Test Mysql With Multiple Replicas",
+			mutate: func(r *GhostApp) {
+				replicas := int32(3)
+				r.Spec.Replicas = &replicas
+				r.Spec.Config.Database.Client = "mysql"
+				r.Spec.Config.Database.Connection.Host = "mysql"
+			},
+		},
+		{
+			name: "Test Mysql Without Host",
+			mutate: func(r *GhostApp) {
+				r.Spec.Config.Database.Client = "mysql"
+			},
+			wantField: "spec.config.database.connection.host",
+		},
+		{
+			name: "Test Ingress TLS With Host From URL",
+			mutate: func(r *GhostApp) {
+				r.Spec.Ingress.Enabled = true
+				r.Spec.Ingress.TLS.Enabled = true
+			},
+		},
+		{
+			name: "Test Ingress TLS Without Hosts",
+			mutate: func(r *GhostApp) {
+				r.Spec.Config.URL = "http://localhost:2368"
+				r.Spec.Ingress.Enabled = true
+				r.Spec.Ingress.TLS.Enabled = true
+			},
+			wantField: "spec.ingress.hosts",
+		},
+		{
+			name: "Test Admin With Host",
+			mutate: func(r *GhostApp) {
+				r.Spec.Ingress.Enabled = true
+				r.Spec.Admin = GhostAppAdminSpec{Enabled: true, Host: "ghost-admin.internal.test"}
+			},
+		},
+		{
+			name: "Test Admin Without Host",
+			mutate: func(r *GhostApp) {
+				r.Spec.Admin = GhostAppAdminSpec{Enabled: true}
+			},
+			wantField: "spec.admin.host",
+		},
+		{
+			name: "Test Admin Host Is Ingress Host",
+			mutate: func(r *GhostApp) {
+				r.Spec.Ingress.Enabled = true
+				r.Spec.Ingress.Hosts = []string{"example.ghostapp.test"}
+				r.Spec.Admin = GhostAppAdminSpec{Enabled: true, Host: "example.ghostapp.test"}
+			},
+			wantField: "spec.admin.host",
+		},
+		{
+			name: "Test Admin URL Does Not Match Admin Host",
+			mutate: func(r *GhostApp) {
+				r.Spec.Config.Admin = &GhostAdminSpec{URL: "https://admin.ghostapp.test"}
+				r.Spec.Admin = GhostAppAdminSpec{Enabled: true, Host: "ghost-admin.internal.test"}
+			},
+			wantField: "spec.config.admin.url",
+		},
+		{
+			name: "Test Valid SMTP Mail",
+			mutate: func(r *GhostApp) {
+				r.Spec.Config.Mail = &GhostMailSpec{
+					Transport: "SMTP",
+					From:      "'Ghost' <noreply@example.ghostapp.test>",
+					Options: &GhostMailOptionsSpec{
+						Service: "Mailgun",
+						Auth: &GhostMailAuthSpec{
+							UserSecretRef: secretRef,
+							PassSecretRef: secretRef,
+						},
+					},
+				}
+			},
+		},
+		{
+			name: "Test Invalid Mail From Address",
+			mutate: func(r *GhostApp) {
+				r.Spec.Config.Mail = &GhostMailSpec{
+					Transport: "SMTP",
+					From:      "noreply",
+					Options:   &GhostMailOptionsSpec{Host: "smtp.example.ghostapp.test"},
+				}
+			},
+			wantField: "spec.config.mail.from",
+		},
+		{
+			name: "Test SMTP Mail Without Host And Service",
+			mutate: func(r *GhostApp) {
+				r.Spec.Config.Mail = &GhostMailSpec{Transport: "SMTP"}
+			},
+			wantField: "spec.config.mail.options.host",
+		},
+		{
+			name: "Test Mail Auth Without Password",
+			mutate: func(r *GhostApp) {
+				r.Spec.Config.Mail = &GhostMailSpec{
+					Transport: "SMTP",
+					Options: &GhostMailOptionsSpec{
+						Host: "smtp.example.ghostapp.test",
+						Auth: &GhostMailAuthSpec{UserSecretRef: secretRef},
+					},
+				}
+			},
+			wantField: "spec.config.mail.options.auth.passSecretRef",
+		},
+		{
+			name: "Test Valid Pod Template",
+			mutate: func(r *GhostApp) {
+				r.Spec.PodTemplate = GhostPodTemplateSpec{
+					Volumes:      []corev1.Volume{{Name: "themes"}},
+					VolumeMounts: []corev1.VolumeMount{{Name: "themes", MountPath: "/var/lib/ghost/content/themes/casper"}},
+					Sidecars:     []corev1.Container{{Name: "exporter", Image: "exporter"}},
+				}
+			},
+		},
+		{
+			name: "Test Pod Template With Reserved Volume Name",
+			mutate: func(r *GhostApp) {
+				r.Spec.PodTemplate.Volumes = []corev1.Volume{{Name: "ghost-content"}}
+			},
+			wantField: "spec.podTemplate.volumes[0].name",
+		},
+		{
+			name: "Test Pod Template With Reserved Mount Path",
+			mutate: func(r *GhostApp) {
+				r.Spec.PodTemplate.VolumeMounts = []corev1.VolumeMount{{Name: "content", MountPath: "/var/lib/ghost/content"}}
+			},
+			wantField: "spec.podTemplate.volumeMounts[0].mountPath",
+		},
+		{
+			name: "Test Pod Template Mounting Tmp",
+			mutate: func(r *GhostApp) {
+				r.Spec.PodTemplate.VolumeMounts = []corev1.VolumeMount{{Name: "cache", MountPath: "/tmp"}}
+			},
+		},
+		{
+			name: "Test Pod Template Mounting Tmp With Read-Only Root Filesystem",
+			mutate: func(r *GhostApp) {
+				r.Spec.Security.ReadOnlyRootFilesystem = true
+				r.Spec.PodTemplate.VolumeMounts = []corev1.VolumeMount{{Name: "cache", MountPath: "/tmp"}}
+			},
+			wantField: "spec.podTemplate.volumeMounts[0].mountPath",
+		},
+		{
+			name: "Test Pod Template With Reserved Sidecar Name",
+			mutate: func(r *GhostApp) {
+				r.Spec.PodTemplate.Sidecars = []corev1.Container{{Name: "ghost", Image: "ghost:3"}}
+			},
+			wantField: "spec.podTemplate.sidecars[0].name",
+		},
+		{
+			name: "Test Ingress TLS Certificates",
+			mutate: func(r *GhostApp) {
+				r.Spec.Ingress = GhostIngressSpec{
+					Enabled: true,
+					Hosts:   []string{"example.com", "blog.example.org"},
+					TLS: GhostIngressTLSSpec{
+						Enabled: true,
+						Certificates: []GhostIngressTLSCertificateSpec{
+							{Hosts: []string{"example.com"}, SecretName: "example-com-tls"},
+							{Hosts: []string{"blog.example.org"}},
+						},
+					},
+				}
+			},
+		},
+		{
+			name: "Test Ingress TLS Certificate With Unknown Host",
+			mutate: func(r *GhostApp) {
+				r.Spec.Ingress = GhostIngressSpec{
+					Enabled: true,
+					Hosts:   []string{"example.com"},
+					TLS: GhostIngressTLSSpec{
+						Enabled: true,
+						Certificates: []GhostIngressTLSCertificateSpec{
+							{Hosts: []string{"www.example.com"}, SecretName: "example-com-tls"},
+						},
+					},
+				}
+			},
+			wantField: "spec.ingress.tls.certificates[0].hosts[0]",
+		},
+		{
+			name: "Test Ingress TLS Issuer With Certificates",
+			mutate: func(r *GhostApp) {
+				r.Spec.Ingress = GhostIngressSpec{
+					Enabled: true,
+					Hosts:   []string{"example.com"},
+					TLS: GhostIngressTLSSpec{
+						Enabled:      true,
+						IssuerRef:    &GhostCertificateIssuerReference{Name: "letsencrypt"},
+						Certificates: []GhostIngressTLSCertificateSpec{{Hosts: []string{"example.com"}}},
+					},
+				}
+			},
+			wantField: "spec.ingress.tls.issuerRef",
+		},
+		{
+			name: "Test Gateway Without Parent",
+			mutate: func(r *GhostApp) {
+				r.Spec.Gateway.Enabled = true
+			},
+			wantField: "spec.gateway.parentRef.name",
+		},
+		{
+			name: "Test Edge Route With Destination CA",
+			mutate: func(r *GhostApp) {
+				r.Spec.Route = GhostRouteSpec{
+					Enabled: true,
+					TLS: &GhostRouteTLSSpec{
+						Termination:              GhostRouteTLSTerminationEdge,
+						DestinationCACertificate: "-----BEGIN CERTIFICATE-----",
+					},
+				}
+			},
+			wantField: "spec.route.tls.destinationCACertificate",
+		},
+		{
+			name: "Test Relative Ingress Path",
+			mutate: func(r *GhostApp) {
+				r.Spec.Ingress.Enabled = true
+				r.Spec.Ingress.Path = "blog"
+			},
+			wantField: "spec.ingress.path",
+		},
+		{
+			name: "Test Valid LoadBalancer Service",
+			mutate: func(r *GhostApp) {
+				r.Spec.Service = GhostServiceSpec{
+					Type:                     corev1.ServiceTypeLoadBalancer,
+					NodePort:                 30080,
+					LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+					ExternalTrafficPolicy:    corev1.ServiceExternalTrafficPolicyTypeLocal,
+				}
+			},
+		},
+		{
+			name: "Test ClusterIP Service With Node Port",
+			mutate: func(r *GhostApp) {
+				r.Spec.Service.NodePort = 30080
+			},
+			wantField: "spec.service.nodePort",
+		},
+		{
+			name: "Test NodePort Service With Load Balancer Source Ranges",
+			mutate: func(r *GhostApp) {
+				r.Spec.Service.Type = corev1.ServiceTypeNodePort
+				r.Spec.Service.LoadBalancerSourceRanges = []string{"10.0.0.0/8"}
+			},
+			wantField: "spec.service.loadBalancerSourceRanges",
+		},
+		{
+			name: "Test Run As Root",
+			mutate: func(r *GhostApp) {
+				root := int64(0)
+				r.Spec.Security.RunAsUser = &root
+			},
+			wantField: "spec.security.runAsUser",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestGhostApp()
+			tt.mutate(r)
+
+			err := r.Validate()
+			if err != nil && tt.wantField == "" {
+				t.Fatalf("validate: (%v)", err)
+			}
+
+			if tt.wantField != "" && !containsString(invalidFields(err), tt.wantField) {
+				t.Fatalf("validate: (%v), want error for %s", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestValidateTransition(t *testing.T) {
+	standard := "standard"
+	premium := "premium"
+
+	tests := []struct {
+		name      string
+		old       func(r *GhostApp)
+		mutate    func(r *GhostApp)
+		wantField string
+	}{
+		{
+			name: "Test Growing Persistent Size",
+			old: func(r *GhostApp) {
+				r.Spec.Persistent = GhostPersistentSpec{Enabled: true, Size: resource.MustParse("10Gi"), StorageClass: &standard}
+			},
+			mutate: func(r *GhostApp) {
+				r.Spec.Persistent.Size = resource.MustParse("20Gi")
+			},
+		},
+		{
+			name: "Test Shrinking Persistent Size",
+			old: func(r *GhostApp) {
+				r.Spec.Persistent = GhostPersistentSpec{Enabled: true, Size: resource.MustParse("10Gi")}
+			},
+			mutate: func(r *GhostApp) {
+				r.Spec.Persistent.Size = resource.MustParse("5Gi")
+			},
+			wantField: "spec.persistent.size",
+		},
+		{
+			name: "Test Changing Storage Class",
+			old: func(r *GhostApp) {
+				r.Spec.Persistent = GhostPersistentSpec{Enabled: true, Size: resource.MustParse("10Gi"), StorageClass: &standard}
+			},
+			mutate: func(r *GhostApp) {
+				r.Spec.Persistent.StorageClass = &premium
+			},
+			wantField: "spec.persistent.storageClass",
+		},
+		{
+			name: "Test Removing Finalizer Of Invalid GhostApp",
+			old: func(r *GhostApp) {
+				r.Spec.Config.URL = "example.ghostapp.test"
+				r.SetFinalizers([]string{"ghost.fossil.or.id/finalizer"})
+			},
+			mutate: func(r *GhostApp) {
+				r.SetFinalizers(nil)
+			},
+		},
+		{
+			name: "Test Updating Invalid GhostApp Being Deleted",
+			old: func(r *GhostApp) {
+				r.Spec.Config.URL = "example.ghostapp.test"
+				now := metav1.Now()
+				r.SetDeletionTimestamp(&now)
+			},
+			mutate: func(r *GhostApp) {
+				r.SetAnnotations(map[string]string{"example.ghostapp.test/note": "deleting"})
+			},
+		},
+		{
+			name: "Test Changing Spec Of Invalid GhostApp",
+			old: func(r *GhostApp) {
+				r.Spec.Config.URL = "example.ghostapp.test"
+			},
+			mutate: func(r *GhostApp) {
+				r.Spec.Image = "ghost:3"
+			},
+			wantField: "spec.config.url",
+		},
+		{
+			name: "Test Switching Database Client",
+			old:  func(r *GhostApp) {},
+			mutate: func(r *GhostApp) {
+				r.Spec.Config.Database.Client = "mysql"
+				r.Spec.Config.Database.Connection.Host = "mysql"
+			},
+			wantField: "spec.config.database.client",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := newTestGhostApp()
+			tt.old(old)
+			r := old.DeepCopy()
+			tt.mutate(r)
+
+			err := r.ValidateUpdate(old)
+			if err != nil && tt.wantField == "" {
+				t.Fatalf("validate update: (%v)", err)
+			}
+
+			if tt.wantField != "" && !containsString(invalidFields(err), tt.wantField) {
+				t.Fatalf("validate update: (%v), want error for %s", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestValidateAcceptedTransition(t *testing.T) {
+	standard := "standard"
+
+	tests := []struct {
+		name      string
+		mutate    func(r *GhostApp)
+		wantField string
+	}{
+		{
+			name:   "Test Unchanged Spec",
+			mutate: func(r *GhostApp) {},
+		},
+		{
+			name: "Test Switching Database Client",
+			mutate: func(r *GhostApp) {
+				r.Spec.Config.Database.Client = "mysql"
+			},
+			wantField: "spec.config.database.client",
+		},
+		{
+			name: "Test Changing Storage Class",
+			mutate: func(r *GhostApp) {
+				r.Spec.Persistent.StorageClass = nil
+			},
+			wantField: "spec.persistent.storageClass",
+		},
+		{
+			name: "Test Disabling Persistent",
+			mutate: func(r *GhostApp) {
+				r.Spec.Persistent.Enabled = false
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestGhostApp()
+			r.Spec.Persistent = GhostPersistentSpec{Enabled: true, Size: resource.MustParse("10Gi"), StorageClass: &standard}
+			r.AcceptSpec()
+			tt.mutate(r)
+
+			err := r.ValidateAcceptedTransition()
+			if err != nil && tt.wantField == "" {
+				t.Fatalf("validate accepted transition: (%v)", err)
+			}
+
+			if tt.wantField != "" && !containsString(invalidFields(err), tt.wantField) {
+				t.Fatalf("validate accepted transition: (%v), want error for %s", err, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2020 Fossil Dev
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+const (
+	// DefaultImage is ghost container image used when spec.image is not defined.
+	DefaultImage = "ghost:latest"
+	// DefaultServerHost is ghost server host. Ghost listens on 127.0.0.1 by default,
+	// we need 0.0.0.0, so ingress controller can resolve this application.
+	DefaultServerHost = "0.0.0.0"
+	// DefaultServerPort is ghost server port.
+	DefaultServerPort = 2368
+	// DefaultServicePort is port exposed by ghost service.
+	DefaultServicePort = 2368
+	// DefaultIssuerKind is kind of cert-manager issuer when spec.ingress.tls.issuerRef.kind is not defined.
+	DefaultIssuerKind = "Issuer"
+	// DefaultIssuerGroup is API group of cert-manager issuer when spec.ingress.tls.issuerRef.group is not defined.
+	DefaultIssuerGroup = "cert-manager.io"
+	// DefaultPersistentSize is size of ghost content volume when spec.persistent.size is not defined.
+	DefaultPersistentSize = "10Gi"
+	// DefaultUserID is uid and gid of node user in the official ghost image.
+	DefaultUserID = 1000
+	// DefaultSqliteFilename is sqlite database file inside ghost content volume.
+	DefaultSqliteFilename = "/var/lib/ghost/content/data/ghost.db"
+)
+
+// SetupWebhookWithManager registers GhostApp admission webhooks to the webhook server of mgr.
+func (r *GhostApp) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-ghost-fossil-or-id-v1alpha1-ghostapp,mutating=true,failurePolicy=fail,groups=ghost.fossil.or.id,resources=ghostapps,versions=v1alpha1,name=mghostapp.ghost.fossil.or.id
+
+var _ webhook.Defaulter = &GhostApp{}
+
+// Default implements webhook.Defaulter. It is also called by the reconciler,
+// so GhostApp admitted without defaulting webhook gets the same defaults.
+func (r *GhostApp) Default() {
+	if r.Spec.Image == "" {
+		r.Spec.Image = DefaultImage
+	}
+
+	if r.Spec.Replicas == nil {
+		replicas := int32(1)
+		r.Spec.Replicas = &replicas
+	}
+
+	if r.Spec.DeletionPolicy == "" {
+		r.Spec.DeletionPolicy = GhostAppDeletionPolicyDelete
+	}
+
+	server := &r.Spec.Config.Server
+	if server.Host == "" {
+		server.Host = DefaultServerHost
+	}
+	if server.Port.IntValue() == 0 {
+		server.Port = intstr.FromInt(DefaultServerPort)
+	}
+
+	security := &r.Spec.Security
+	if security.RunAsUser == nil {
+		security.RunAsUser = int64Ptr(DefaultUserID)
+	}
+	if security.RunAsGroup == nil {
+		security.RunAsGroup = int64Ptr(DefaultUserID)
+	}
+	if security.FSGroup == nil {
+		security.FSGroup = int64Ptr(DefaultUserID)
+	}
+
+	if r.Spec.Ingress.Enabled {
+		if r.Spec.Ingress.PathType == "" {
+			r.Spec.Ingress.PathType = GhostIngressPathTypePrefix
+		}
+		if issuerRef := r.Spec.Ingress.TLS.IssuerRef; issuerRef != nil {
+			if issuerRef.Kind == "" {
+				issuerRef.Kind = DefaultIssuerKind
+			}
+			if issuerRef.Group == "" {
+				issuerRef.Group = DefaultIssuerGroup
+			}
+		}
+	}
+
+	if tls := r.Spec.Route.TLS; r.Spec.Route.Enabled && tls != nil {
+		if tls.Termination == "" {
+			tls.Termination = GhostRouteTLSTerminationEdge
+		}
+		if tls.InsecureEdgeTerminationPolicy == "" {
+			tls.InsecureEdgeTerminationPolicy = "Redirect"
+		}
+	}
+
+	if r.Spec.Service.Port == 0 {
+		r.Spec.Service.Port = DefaultServicePort
+	}
+
+	database := &r.Spec.Config.Database
+	if database.Client == "sqlite3" && database.Connection.Filename == "" {
+		database.Connection.Filename = DefaultSqliteFilename
+	}
+
+	if r.Spec.Persistent.Enabled && r.Spec.Persistent.Size.IsZero() {
+		r.Spec.Persistent.Size = resource.MustParse(DefaultPersistentSize)
+	}
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-ghost-fossil-or-id-v1alpha1-ghostapp,mutating=false,failurePolicy=fail,groups=ghost.fossil.or.id,resources=ghostapps,versions=v1alpha1,name=vghostapp.ghost.fossil.or.id
+
+var _ webhook.Validator = &GhostApp{}
+
+// ValidateCreate implements webhook.Validator.
+func (r *GhostApp) ValidateCreate() error {
+	return r.Validate()
+}
+
+// ValidateUpdate implements webhook.Validator.
+func (r *GhostApp) ValidateUpdate(old runtime.Object) error {
+	return r.ValidateTransition(old.(*GhostApp))
+}
+
+// ValidateDelete implements webhook.Validator.
+func (r *GhostApp) ValidateDelete() error {
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 Fossil Dev
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestDefault(t *testing.T) {
+	r := newTestGhostApp()
+	r.Spec.Persistent.Enabled = true
+	r.Default()
+
+	if r.Spec.Image != DefaultImage {
+		t.Errorf("image = %q, want %q", r.Spec.Image, DefaultImage)
+	}
+	if r.Spec.Replicas == nil || *r.Spec.Replicas != 1 {
+		t.Errorf("replicas = %v, want 1", r.Spec.Replicas)
+	}
+	if r.Spec.DeletionPolicy != GhostAppDeletionPolicyDelete {
+		t.Errorf("deletionPolicy = %q, want %q", r.Spec.DeletionPolicy, GhostAppDeletionPolicyDelete)
+	}
+	if r.Spec.Config.Server.Host != DefaultServerHost || r.Spec.Config.Server.Port != intstr.FromInt(DefaultServerPort) {
+		t.Errorf("server = %v, want %s:%d", r.Spec.Config.Server, DefaultServerHost, DefaultServerPort)
+	}
+	if r.Spec.Service.Port != DefaultServicePort {
+		t.Errorf("service port = %d, want %d", r.Spec.Service.Port, DefaultServicePort)
+	}
+	security := r.Spec.Security
+	if security.RunAsUser == nil || *security.RunAsUser != DefaultUserID ||
+		security.RunAsGroup == nil || *security.RunAsGroup != DefaultUserID ||
+		security.FSGroup == nil || *security.FSGroup != DefaultUserID {
+		t.Errorf("security = %+v, want runAsUser, runAsGroup and fsGroup %d", security, DefaultUserID)
+	}
+	if r.Spec.Config.Database.Connection.Filename != DefaultSqliteFilename {
+		t.Errorf("sqlite filename = %q, want %q", r.Spec.Config.Database.Connection.Filename, DefaultSqliteFilename)
+	}
+	if r.Spec.Persistent.Size.Cmp(resource.MustParse(DefaultPersistentSize)) != 0 {
+		t.Errorf("persistent size = %v, want %s", r.Spec.Persistent.Size.String(), DefaultPersistentSize)
+	}
+
+	// Defaulting must not override values defined by user and must be idempotent.
+	defaulted := r.DeepCopy()
+	defaulted.Spec.Image = "ghost:3"
+	defaulted.Default()
+	if defaulted.Spec.Image != "ghost:3" {
+		t.Errorf("image = %q, want ghost:3", defaulted.Spec.Image)
+	}
+	defaulted.Spec.Image = r.Spec.Image
+	if !reflect.DeepEqual(defaulted, r) {
+		t.Error("defaulting is not idempotent")
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2020 Fossil Dev
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// GhostDatabaseConnectionSpec defines ghost database connection.
+type GhostDatabaseConnectionSpec struct {
+	// sqlite filename.
+	// +optional
+	Filename string `json:"filename,omitempty"`
+	// mysql host
+	// +optional
+	Host string `json:"host,omitempty"`
+	// mysql port
+	// +optional
+	Port intstr.IntOrString `json:"port,omitempty"`
+	// mysql database user
+	// +optional
+	User string `json:"user,omitempty"`
+	// Selects a key of a secret in the GhostApp namespace holding the mysql database user.
+	// Takes precedence over user.
+	// +optional
+	UserSecretRef *corev1.SecretKeySelector `json:"userSecretRef,omitempty"`
+	// mysql database password of user.
+	// Deprecated: this value is stored in plaintext, use passwordSecretRef instead.
+	// +optional
+	Password string `json:"password,omitempty"`
+	// Selects a key of a secret in the GhostApp namespace holding the mysql database password of user.
+	// Takes precedence over password.
+	// +optional
+	PasswordSecretRef *corev1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+	// mysql database name
+	// +optional
+	Database string `json:"database,omitempty"`
+}
+
+// GhostServerSpec defines ghost server config.
+// https://ghost.org/docs/concepts/config/#server
+type GhostServerSpec struct {
+	Host string             `json:"host"`
+	Port intstr.IntOrString `json:"port"`
+}
+
+// GhostDatabaseSpec defines ghost database config.
+// https://ghost.org/docs/concepts/config/#database
+type GhostDatabaseSpec struct {
+	// Client is ghost database client.
+	// +kubebuilder:validation:Enum=sqlite3;mysql
+	Client string `json:"client"`
+	// +optional
+	Connection GhostDatabaseConnectionSpec `json:"connection"`
+}
+
+// GhostAdminSpec defines ghost admin config.
+// https://ghost.org/docs/concepts/config/#admin-url
+type GhostAdminSpec struct {
+	// URL is the protocol and hostname for ghost admin panel.
+	// +optional
+	URL string `json:"url,omitempty"`
+}
+
+// GhostMailAuthSpec defines credentials of ghost mail transport.
+// Credentials are always read from secrets, so they are never stored in plaintext.
+type GhostMailAuthSpec struct {
+	// Selects a key of a secret in the GhostApp namespace holding the mail user.
+	UserSecretRef *corev1.SecretKeySelector `json:"userSecretRef"`
+	// Selects a key of a secret in the GhostApp namespace holding the mail password.
+	PassSecretRef *corev1.SecretKeySelector `json:"passSecretRef"`
+}
+
+// GhostMailOptionsSpec defines options of ghost mail transport.
+type GhostMailOptionsSpec struct {
+	// Well known mail service name, eg: Mailgun, Sendgrid, SES.
+	// When defined, host and port of the service are resolved by ghost.
+	// +optional
+	Service string `json:"service,omitempty"`
+	// +optional
+	Host string `json:"host,omitempty"`
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+	// Secure enables TLS when connecting to mail host.
+	// +optional
+	Secure *bool `json:"secure,omitempty"`
+	// +optional
+	Auth *GhostMailAuthSpec `json:"auth,omitempty"`
+}
+
+// GhostMailSpec defines ghost mail config.
+// https://ghost.org/docs/concepts/config/#mail
+type GhostMailSpec struct {
+	// +kubebuilder:validation:Enum=SMTP;Direct
+	Transport string `json:"transport"`
+	// From is the address ghost sends mail from, eg: "'Ghost' <noreply@example.com>".
+	// +optional
+	From string `json:"from,omitempty"`
+	// +optional
+	Options *GhostMailOptionsSpec `json:"options,omitempty"`
+}
+
+// GhostLoggingRotationSpec defines ghost log file rotation.
+type GhostLoggingRotationSpec struct {
+	Enabled bool `json:"enabled"`
+	// Period of rotation, eg: 1d.
+	// +optional
+	Period string `json:"period,omitempty"`
+	// Count of rotated files to keep.
+	// +optional
+	Count *int32 `json:"count,omitempty"`
+}
+
+// GhostLoggingSpec defines ghost logging config.
+// https://ghost.org/docs/concepts/config/#logging
+type GhostLoggingSpec struct {
+	// +optional
+	Level string `json:"level,omitempty"`
+	// +optional
+	Rotation *GhostLoggingRotationSpec `json:"rotation,omitempty"`
+	// Transports of ghost log, eg: file, stdout.
+	// +optional
+	Transports []string `json:"transports,omitempty"`
+	// Path of log files when file transport is used.
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// GhostPrivacySpec defines ghost privacy config.
+// https://ghost.org/docs/concepts/config/#privacy
+type GhostPrivacySpec struct {
+	// UseTinfoil disables all features below at once.
+	// +optional
+	UseTinfoil *bool `json:"useTinfoil,omitempty"`
+	// +optional
+	UseUpdateCheck *bool `json:"useUpdateCheck,omitempty"`
+	// +optional
+	UseGravatar *bool `json:"useGravatar,omitempty"`
+	// +optional
+	UseRPCPing *bool `json:"useRpcPing,omitempty"`
+	// +optional
+	UseStructuredData *bool `json:"useStructuredData,omitempty"`
+}
+
+// GhostPathsSpec defines ghost paths config.
+// https://ghost.org/docs/concepts/config/#content-path
+type GhostPathsSpec struct {
+	// ContentPath is the path of ghost content directory.
+	// NOTE: ghost content volume is always mounted in /var/lib/ghost/content.
+	// +optional
+	ContentPath string `json:"contentPath,omitempty"`
+}
+
+// GhostImageOptimizationSpec defines ghost image optimization config.
+// https://ghost.org/docs/concepts/config/#image-optimisation
+type GhostImageOptimizationSpec struct {
+	// +optional
+	Resize *bool `json:"resize,omitempty"`
+}
+
+// GhostCacheSpec defines cache control of a ghost response type.
+type GhostCacheSpec struct {
+	// MaxAge in seconds.
+	MaxAge int32 `json:"maxAge"`
+}
+
+// GhostCachingSpec defines ghost caching config.
+// https://ghost.org/docs/concepts/config/#caching
+type GhostCachingSpec struct {
+	// +optional
+	Frontend *GhostCacheSpec `json:"frontend,omitempty"`
+	// +optional
+	Redirect301 *GhostCacheSpec `json:"301,omitempty"`
+	// +optional
+	CustomRedirects *GhostCacheSpec `json:"customRedirects,omitempty"`
+	// +optional
+	Favicon *GhostCacheSpec `json:"favicon,omitempty"`
+	// +optional
+	Sitemap *GhostCacheSpec `json:"sitemap,omitempty"`
+	// +optional
+	Robotstxt *GhostCacheSpec `json:"robotstxt,omitempty"`
+	// +optional
+	PublicAssets *GhostCacheSpec `json:"publicAssets,omitempty"`
+}
+
+// GhostSpamLimitSpec defines brute force protection of a ghost endpoint.
+type GhostSpamLimitSpec struct {
+	// +optional
+	MinWait *int32 `json:"minWait,omitempty"`
+	// +optional
+	MaxWait *int32 `json:"maxWait,omitempty"`
+	// +optional
+	Lifetime *int32 `json:"lifetime,omitempty"`
+	// +optional
+	FreeRetries *int32 `json:"freeRetries,omitempty"`
+}
+
+// GhostSpamSpec defines ghost spam config.
+// https://ghost.org/docs/concepts/config/#spam
+type GhostSpamSpec struct {
+	// +optional
+	UserLogin *GhostSpamLimitSpec `json:"user_login,omitempty"`
+	// +optional
+	UserReset *GhostSpamLimitSpec `json:"user_reset,omitempty"`
+	// +optional
+	GlobalReset *GhostSpamLimitSpec `json:"global_reset,omitempty"`
+	// +optional
+	GlobalBlock *GhostSpamLimitSpec `json:"global_block,omitempty"`
+	// +optional
+	PrivateBlock *GhostSpamLimitSpec `json:"private_block,omitempty"`
+	// +optional
+	ContentAPIKey *GhostSpamLimitSpec `json:"content_api_key,omitempty"`
+}
+
+// GhostStorageSpec defines ghost storage adapter config.
+// https://ghost.org/docs/concepts/config/#storage-adapters
+type GhostStorageSpec struct {
+	// Active is the name of storage adapter used by ghost.
+	// +optional
+	Active string `json:"active,omitempty"`
+	// Options of the active storage adapter. Written as storage.<active> in ghost configuration.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Options *runtime.RawExtension `json:"options,omitempty"`
+}
+
+// GhostConfigSpec defines related ghost configuration based on https://ghost.org/docs/concepts/config
+type GhostConfigSpec struct {
+	URL string `json:"url"`
+	// +optional
+	Admin *GhostAdminSpec `json:"admin,omitempty"`
+
+	Database GhostDatabaseSpec `json:"database"`
+	// +optional
+	Server GhostServerSpec `json:"server"`
+	// +optional
+	Mail *GhostMailSpec `json:"mail,omitempty"`
+	// +optional
+	Logging *GhostLoggingSpec `json:"logging,omitempty"`
+	// +optional
+	Privacy *GhostPrivacySpec `json:"privacy,omitempty"`
+	// +optional
+	Paths *GhostPathsSpec `json:"paths,omitempty"`
+	// +optional
+	ImageOptimization *GhostImageOptimizationSpec `json:"imageOptimization,omitempty"`
+	// +optional
+	Caching *GhostCachingSpec `json:"caching,omitempty"`
+	// ReferrerPolicy sets the referrer policy of ghost site, eg: origin-when-crossorigin.
+	// +optional
+	ReferrerPolicy string `json:"referrerPolicy,omitempty"`
+	// +optional
+	Spam *GhostSpamSpec `json:"spam,omitempty"`
+	// +optional
+	Storage *GhostStorageSpec `json:"storage,omitempty"`
+	// Extra is arbitrary ghost configuration deep-merged into the rendered configuration.
+	// Use this for ghost options that are not modeled by this spec. Values in extra take precedence.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Extra *runtime.RawExtension `json:"extra,omitempty"`
+}
@@ -14,6 +14,15 @@
 
 package v1alpha1
 
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 func (r *GhostApp) IsPersistentEnabled() bool {
 	if r.Spec.Persistent.Enabled {
 		return true
@@ -29,3 +38,122 @@ func (r *GhostApp) IsIngressEnabled() bool {
 
 	return false
 }
+
+// IngressHosts returns hosts routed to ghost. Host of config.url is used when spec.ingress.hosts is empty,
+// unless it is an IP address or a single label name like localhost, which is routed by a catch-all rule instead.
+func (r *GhostApp) IngressHosts() []string {
+	if len(r.Spec.Ingress.Hosts) > 0 {
+		return r.Spec.Ingress.Hosts
+	}
+
+	u, err := url.Parse(r.Spec.Config.URL)
+	if err != nil {
+		return nil
+	}
+
+	host := u.Hostname()
+	if net.ParseIP(host) != nil || !strings.Contains(host, ".") {
+		return nil
+	}
+
+	return []string{host}
+}
+
+// IngressPath returns path routed to ghost. Path of config.url is used when spec.ingress.path is not defined,
+// so ghost running under a subdirectory only receives requests for that subdirectory.
+func (r *GhostApp) IngressPath() string {
+	if r.Spec.Ingress.Path != "" {
+		return r.Spec.Ingress.Path
+	}
+
+	if u, err := url.Parse(r.Spec.Config.URL); err == nil {
+		if path := strings.TrimSuffix(u.Path, "/"); path != "" {
+			return path
+		}
+	}
+
+	return "/"
+}
+
+// IsGatewayEnabled returns true when ghost is exposed through a Gateway API HTTPRoute.
+func (r *GhostApp) IsGatewayEnabled() bool {
+	return r.Spec.Gateway.Enabled
+}
+
+// IsRouteEnabled returns true when ghost is exposed through OpenShift Routes.
+func (r *GhostApp) IsRouteEnabled() bool {
+	return r.Spec.Route.Enabled
+}
+
+// IsAdminEnabled returns true when ghost admin panel is served through a separate admin ingress.
+func (r *GhostApp) IsAdminEnabled() bool {
+	return r.Spec.Admin.Enabled
+}
+
+// AdminURL returns admin.url of ghost configuration. config.admin.url takes precedence over the URL of admin host,
+// it is empty when neither is defined, so ghost serves its admin panel on config.url.
+func (r *GhostApp) AdminURL() string {
+	if r.Spec.Config.Admin != nil && r.Spec.Config.Admin.URL != "" {
+		return r.Spec.Config.Admin.URL
+	}
+
+	if !r.IsAdminEnabled() || r.Spec.Admin.Host == "" {
+		return ""
+	}
+
+	scheme := "http"
+	if r.Spec.Admin.TLS.Enabled {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Spec.Admin.Host
+}
+
+// ServiceType returns type of ghost service. NodePort is used when ingress is enabled and the type is not defined.
+func (r *GhostApp) ServiceType() corev1.ServiceType {
+	if r.Spec.Service.Type != "" {
+		return r.Spec.Service.Type
+	}
+
+	if r.IsIngressEnabled() {
+		return corev1.ServiceTypeNodePort
+	}
+
+	return corev1.ServiceTypeClusterIP
+}
+
+// GetCondition returns condition of r status with the given type, or nil when it does not exist.
+func (r *GhostApp) GetCondition(conditionType GhostAppConditionType) *GhostAppCondition {
+	for i := range r.Status.Conditions {
+		if r.Status.Conditions[i].Type == conditionType {
+			return &r.Status.Conditions[i]
+		}
+	}
+
+	return nil
+}
+
+// IsConditionTrue returns true when condition of r status with the given type has status True.
+func (r *GhostApp) IsConditionTrue(conditionType GhostAppConditionType) bool {
+	condition := r.GetCondition(conditionType)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
+
+// SetCondition adds or updates condition of r status with the given type.
+// LastTransitionTime is only updated when the condition status changes.
+func (r *GhostApp) SetCondition(conditionType GhostAppConditionType, status corev1.ConditionStatus, reason, message string) {
+	condition := r.GetCondition(conditionType)
+	if condition == nil {
+		r.Status.Conditions = append(r.Status.Conditions, GhostAppCondition{Type: conditionType})
+		condition = &r.Status.Conditions[len(r.Status.Conditions)-1]
+	}
+
+	if condition.Status != status {
+		condition.LastTransitionTime = metav1.Now()
+	}
+
+	condition.Status = status
+	condition.ObservedGeneration = r.GetGeneration()
+	condition.Reason = reason
+	condition.Message = message
+}
@@ -5,16 +5,49 @@
 package v1alpha1
 
 import (
+	v1 "k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostAdminSpec) DeepCopyInto(out *GhostAdminSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostAdminSpec.
+func (in *GhostAdminSpec) DeepCopy() *GhostAdminSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostAdminSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostAdminTLSSpec) DeepCopyInto(out *GhostAdminTLSSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostAdminTLSSpec.
+func (in *GhostAdminTLSSpec) DeepCopy() *GhostAdminTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostAdminTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GhostApp) DeepCopyInto(out *GhostApp) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -36,6 +69,97 @@ func (in *GhostApp) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostAppAcceptedSpec) DeepCopyInto(out *GhostAppAcceptedSpec) {
+	*out = *in
+	if in.Persistent != nil {
+		in, out := &in.Persistent, &out.Persistent
+		*out = new(GhostPersistentSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostAppAcceptedSpec.
+func (in *GhostAppAcceptedSpec) DeepCopy() *GhostAppAcceptedSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostAppAcceptedSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostAppAdminSpec) DeepCopyInto(out *GhostAppAdminSpec) {
+	*out = *in
+	out.TLS = in.TLS
+	if in.ClassName != nil {
+		in, out := &in.ClassName, &out.ClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostAppAdminSpec.
+func (in *GhostAppAdminSpec) DeepCopy() *GhostAppAdminSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostAppAdminSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostAppCertificateStatus) DeepCopyInto(out *GhostAppCertificateStatus) {
+	*out = *in
+	if in.NotAfter != nil {
+		in, out := &in.NotAfter, &out.NotAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.RenewalTime != nil {
+		in, out := &in.RenewalTime, &out.RenewalTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostAppCertificateStatus.
+func (in *GhostAppCertificateStatus) DeepCopy() *GhostAppCertificateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostAppCertificateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostAppCondition) DeepCopyInto(out *GhostAppCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostAppCondition.
+func (in *GhostAppCondition) DeepCopy() *GhostAppCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostAppCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GhostAppList) DeepCopyInto(out *GhostAppList) {
 	*out = *in
@@ -69,6 +193,38 @@ func (in *GhostAppList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostAppPodStatus) DeepCopyInto(out *GhostAppPodStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostAppPodStatus.
+func (in *GhostAppPodStatus) DeepCopy() *GhostAppPodStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostAppPodStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostAppRouteStatus) DeepCopyInto(out *GhostAppRouteStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostAppRouteStatus.
+func (in *GhostAppRouteStatus) DeepCopy() *GhostAppRouteStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostAppRouteStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GhostAppSpec) DeepCopyInto(out *GhostAppSpec) {
 	*out = *in
@@ -77,9 +233,16 @@ func (in *GhostAppSpec) DeepCopyInto(out *GhostAppSpec) {
 		*out = new(int32)
 		**out = **in
 	}
-	out.Config = in.Config
+	in.Config.DeepCopyInto(&out.Config)
 	in.Persistent.DeepCopyInto(&out.Persistent)
+	in.Probes.DeepCopyInto(&out.Probes)
+	in.Security.DeepCopyInto(&out.Security)
+	in.PodTemplate.DeepCopyInto(&out.PodTemplate)
+	in.Service.DeepCopyInto(&out.Service)
 	in.Ingress.DeepCopyInto(&out.Ingress)
+	out.Gateway = in.Gateway
+	in.Route.DeepCopyInto(&out.Route)
+	in.Admin.DeepCopyInto(&out.Admin)
 	return
 }
 
@@ -96,6 +259,33 @@ func (in *GhostAppSpec) DeepCopy() *GhostAppSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GhostAppStatus) DeepCopyInto(out *GhostAppStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]GhostAppCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UnhealthyPods != nil {
+		in, out := &in.UnhealthyPods, &out.UnhealthyPods
+		*out = make([]GhostAppPodStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Routes != nil {
+		in, out := &in.Routes, &out.Routes
+		*out = make([]GhostAppRouteStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Certificate != nil {
+		in, out := &in.Certificate, &out.Certificate
+		*out = new(GhostAppCertificateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AcceptedSpec != nil {
+		in, out := &in.AcceptedSpec, &out.AcceptedSpec
+		*out = new(GhostAppAcceptedSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -109,11 +299,144 @@ func (in *GhostAppStatus) DeepCopy() *GhostAppStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostCacheSpec) DeepCopyInto(out *GhostCacheSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostCacheSpec.
+func (in *GhostCacheSpec) DeepCopy() *GhostCacheSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostCacheSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostCachingSpec) DeepCopyInto(out *GhostCachingSpec) {
+	*out = *in
+	if in.Frontend != nil {
+		in, out := &in.Frontend, &out.Frontend
+		*out = new(GhostCacheSpec)
+		**out = **in
+	}
+	if in.Redirect301 != nil {
+		in, out := &in.Redirect301, &out.Redirect301
+		*out = new(GhostCacheSpec)
+		**out = **in
+	}
+	if in.CustomRedirects != nil {
+		in, out := &in.CustomRedirects, &out.CustomRedirects
+		*out = new(GhostCacheSpec)
+		**out = **in
+	}
+	if in.Favicon != nil {
+		in, out := &in.Favicon, &out.Favicon
+		*out = new(GhostCacheSpec)
+		**out = **in
+	}
+	if in.Sitemap != nil {
+		in, out := &in.Sitemap, &out.Sitemap
+		*out = new(GhostCacheSpec)
+		**out = **in
+	}
+	if in.Robotstxt != nil {
+		in, out := &in.Robotstxt, &out.Robotstxt
+		*out = new(GhostCacheSpec)
+		**out = **in
+	}
+	if in.PublicAssets != nil {
+		in, out := &in.PublicAssets, &out.PublicAssets
+		*out = new(GhostCacheSpec)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostCachingSpec.
+func (in *GhostCachingSpec) DeepCopy() *GhostCachingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostCachingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostCertificateIssuerReference) DeepCopyInto(out *GhostCertificateIssuerReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostCertificateIssuerReference.
+func (in *GhostCertificateIssuerReference) DeepCopy() *GhostCertificateIssuerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostCertificateIssuerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GhostConfigSpec) DeepCopyInto(out *GhostConfigSpec) {
 	*out = *in
-	out.Database = in.Database
+	if in.Admin != nil {
+		in, out := &in.Admin, &out.Admin
+		*out = new(GhostAdminSpec)
+		**out = **in
+	}
+	in.Database.DeepCopyInto(&out.Database)
 	out.Server = in.Server
+	if in.Mail != nil {
+		in, out := &in.Mail, &out.Mail
+		*out = new(GhostMailSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Logging != nil {
+		in, out := &in.Logging, &out.Logging
+		*out = new(GhostLoggingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Privacy != nil {
+		in, out := &in.Privacy, &out.Privacy
+		*out = new(GhostPrivacySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = new(GhostPathsSpec)
+		**out = **in
+	}
+	if in.ImageOptimization != nil {
+		in, out := &in.ImageOptimization, &out.ImageOptimization
+		*out = new(GhostImageOptimizationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Caching != nil {
+		in, out := &in.Caching, &out.Caching
+		*out = new(GhostCachingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Spam != nil {
+		in, out := &in.Spam, &out.Spam
+		*out = new(GhostSpamSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(GhostStorageSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Extra != nil {
+		in, out := &in.Extra, &out.Extra
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -131,6 +454,16 @@ func (in *GhostConfigSpec) DeepCopy() *GhostConfigSpec {
 func (in *GhostDatabaseConnectionSpec) DeepCopyInto(out *GhostDatabaseConnectionSpec) {
 	*out = *in
 	out.Port = in.Port
+	if in.UserSecretRef != nil {
+		in, out := &in.UserSecretRef, &out.UserSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PasswordSecretRef != nil {
+		in, out := &in.PasswordSecretRef, &out.PasswordSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -147,7 +480,7 @@ func (in *GhostDatabaseConnectionSpec) DeepCopy() *GhostDatabaseConnectionSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GhostDatabaseSpec) DeepCopyInto(out *GhostDatabaseSpec) {
 	*out = *in
-	out.Connection = in.Connection
+	in.Connection.DeepCopyInto(&out.Connection)
 	return
 }
 
@@ -161,6 +494,60 @@ func (in *GhostDatabaseSpec) DeepCopy() *GhostDatabaseSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostGatewayParentReference) DeepCopyInto(out *GhostGatewayParentReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostGatewayParentReference.
+func (in *GhostGatewayParentReference) DeepCopy() *GhostGatewayParentReference {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostGatewayParentReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostGatewaySpec) DeepCopyInto(out *GhostGatewaySpec) {
+	*out = *in
+	out.ParentRef = in.ParentRef
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostGatewaySpec.
+func (in *GhostGatewaySpec) DeepCopy() *GhostGatewaySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostGatewaySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostImageOptimizationSpec) DeepCopyInto(out *GhostImageOptimizationSpec) {
+	*out = *in
+	if in.Resize != nil {
+		in, out := &in.Resize, &out.Resize
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostImageOptimizationSpec.
+func (in *GhostImageOptimizationSpec) DeepCopy() *GhostImageOptimizationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostImageOptimizationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GhostIngressSpec) DeepCopyInto(out *GhostIngressSpec) {
 	*out = *in
@@ -169,7 +556,12 @@ func (in *GhostIngressSpec) DeepCopyInto(out *GhostIngressSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	out.TLS = in.TLS
+	in.TLS.DeepCopyInto(&out.TLS)
+	if in.ClassName != nil {
+		in, out := &in.ClassName, &out.ClassName
+		*out = new(string)
+		**out = **in
+	}
 	if in.Annotations != nil {
 		in, out := &in.Annotations, &out.Annotations
 		*out = make(map[string]string, len(*in))
@@ -191,47 +583,494 @@ func (in *GhostIngressSpec) DeepCopy() *GhostIngressSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GhostIngressTLSSpec) DeepCopyInto(out *GhostIngressTLSSpec) {
+func (in *GhostIngressTLSCertificateSpec) DeepCopyInto(out *GhostIngressTLSCertificateSpec) {
 	*out = *in
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostIngressTLSSpec.
-func (in *GhostIngressTLSSpec) DeepCopy() *GhostIngressTLSSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostIngressTLSCertificateSpec.
+func (in *GhostIngressTLSCertificateSpec) DeepCopy() *GhostIngressTLSCertificateSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GhostIngressTLSSpec)
+	out := new(GhostIngressTLSCertificateSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GhostPersistentSpec) DeepCopyInto(out *GhostPersistentSpec) {
+func (in *GhostIngressTLSSpec) DeepCopyInto(out *GhostIngressTLSSpec) {
 	*out = *in
-	if in.StorageClass != nil {
-		in, out := &in.StorageClass, &out.StorageClass
-		*out = new(string)
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = make([]GhostIngressTLSCertificateSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(GhostCertificateIssuerReference)
 		**out = **in
 	}
-	out.Size = in.Size.DeepCopy()
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostPersistentSpec.
-func (in *GhostPersistentSpec) DeepCopy() *GhostPersistentSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostIngressTLSSpec.
+func (in *GhostIngressTLSSpec) DeepCopy() *GhostIngressTLSSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GhostPersistentSpec)
+	out := new(GhostIngressTLSSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GhostServerSpec) DeepCopyInto(out *GhostServerSpec) {
+func (in *GhostLoggingRotationSpec) DeepCopyInto(out *GhostLoggingRotationSpec) {
 	*out = *in
-	out.Port = in.Port
+	if in.Count != nil {
+		in, out := &in.Count, &out.Count
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostLoggingRotationSpec.
+func (in *GhostLoggingRotationSpec) DeepCopy() *GhostLoggingRotationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostLoggingRotationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostLoggingSpec) DeepCopyInto(out *GhostLoggingSpec) {
+	*out = *in
+	if in.Rotation != nil {
+		in, out := &in.Rotation, &out.Rotation
+		*out = new(GhostLoggingRotationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Transports != nil {
+		in, out := &in.Transports, &out.Transports
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostLoggingSpec.
+func (in *GhostLoggingSpec) DeepCopy() *GhostLoggingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostLoggingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostMailAuthSpec) DeepCopyInto(out *GhostMailAuthSpec) {
+	*out = *in
+	if in.UserSecretRef != nil {
+		in, out := &in.UserSecretRef, &out.UserSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PassSecretRef != nil {
+		in, out := &in.PassSecretRef, &out.PassSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostMailAuthSpec.
+func (in *GhostMailAuthSpec) DeepCopy() *GhostMailAuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostMailAuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostMailOptionsSpec) DeepCopyInto(out *GhostMailOptionsSpec) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Secure != nil {
+		in, out := &in.Secure, &out.Secure
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(GhostMailAuthSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostMailOptionsSpec.
+func (in *GhostMailOptionsSpec) DeepCopy() *GhostMailOptionsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostMailOptionsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostMailSpec) DeepCopyInto(out *GhostMailSpec) {
+	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = new(GhostMailOptionsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostMailSpec.
+func (in *GhostMailSpec) DeepCopy() *GhostMailSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostMailSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostPathsSpec) DeepCopyInto(out *GhostPathsSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostPathsSpec.
+func (in *GhostPathsSpec) DeepCopy() *GhostPathsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostPathsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostPersistentSpec) DeepCopyInto(out *GhostPersistentSpec) {
+	*out = *in
+	if in.StorageClass != nil {
+		in, out := &in.StorageClass, &out.StorageClass
+		*out = new(string)
+		**out = **in
+	}
+	out.Size = in.Size.DeepCopy()
+	if in.VolumeSnapshotClassName != nil {
+		in, out := &in.VolumeSnapshotClassName, &out.VolumeSnapshotClassName
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostPersistentSpec.
+func (in *GhostPersistentSpec) DeepCopy() *GhostPersistentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostPersistentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostPodTemplateSpec) DeepCopyInto(out *GhostPodTemplateSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EnvFrom != nil {
+		in, out := &in.EnvFrom, &out.EnvFrom
+		*out = make([]v1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Sidecars != nil {
+		in, out := &in.Sidecars, &out.Sidecars
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostPodTemplateSpec.
+func (in *GhostPodTemplateSpec) DeepCopy() *GhostPodTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostPodTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostPrivacySpec) DeepCopyInto(out *GhostPrivacySpec) {
+	*out = *in
+	if in.UseTinfoil != nil {
+		in, out := &in.UseTinfoil, &out.UseTinfoil
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UseUpdateCheck != nil {
+		in, out := &in.UseUpdateCheck, &out.UseUpdateCheck
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UseGravatar != nil {
+		in, out := &in.UseGravatar, &out.UseGravatar
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UseRPCPing != nil {
+		in, out := &in.UseRPCPing, &out.UseRPCPing
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UseStructuredData != nil {
+		in, out := &in.UseStructuredData, &out.UseStructuredData
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostPrivacySpec.
+func (in *GhostPrivacySpec) DeepCopy() *GhostPrivacySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostPrivacySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostProbeSpec) DeepCopyInto(out *GhostProbeSpec) {
+	*out = *in
+	in.Probe.DeepCopyInto(&out.Probe)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostProbeSpec.
+func (in *GhostProbeSpec) DeepCopy() *GhostProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostProbesSpec) DeepCopyInto(out *GhostProbesSpec) {
+	*out = *in
+	if in.Startup != nil {
+		in, out := &in.Startup, &out.Startup
+		*out = new(GhostProbeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = new(GhostProbeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Liveness != nil {
+		in, out := &in.Liveness, &out.Liveness
+		*out = new(GhostProbeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostProbesSpec.
+func (in *GhostProbesSpec) DeepCopy() *GhostProbesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostProbesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostRouteSpec) DeepCopyInto(out *GhostRouteSpec) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(GhostRouteTLSSpec)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostRouteSpec.
+func (in *GhostRouteSpec) DeepCopy() *GhostRouteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostRouteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostRouteTLSSpec) DeepCopyInto(out *GhostRouteTLSSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostRouteTLSSpec.
+func (in *GhostRouteTLSSpec) DeepCopy() *GhostRouteTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostRouteTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostSecuritySpec) DeepCopyInto(out *GhostSecuritySpec) {
+	*out = *in
+	if in.RunAsUser != nil {
+		in, out := &in.RunAsUser, &out.RunAsUser
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RunAsGroup != nil {
+		in, out := &in.RunAsGroup, &out.RunAsGroup
+		*out = new(int64)
+		**out = **in
+	}
+	if in.FSGroup != nil {
+		in, out := &in.FSGroup, &out.FSGroup
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostSecuritySpec.
+func (in *GhostSecuritySpec) DeepCopy() *GhostSecuritySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostSecuritySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostServerSpec) DeepCopyInto(out *GhostServerSpec) {
+	*out = *in
+	out.Port = in.Port
 	return
 }
 
@@ -244,3 +1083,141 @@ func (in *GhostServerSpec) DeepCopy() *GhostServerSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostServiceSpec) DeepCopyInto(out *GhostServiceSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostServiceSpec.
+func (in *GhostServiceSpec) DeepCopy() *GhostServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostSpamLimitSpec) DeepCopyInto(out *GhostSpamLimitSpec) {
+	*out = *in
+	if in.MinWait != nil {
+		in, out := &in.MinWait, &out.MinWait
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxWait != nil {
+		in, out := &in.MaxWait, &out.MaxWait
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Lifetime != nil {
+		in, out := &in.Lifetime, &out.Lifetime
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FreeRetries != nil {
+		in, out := &in.FreeRetries, &out.FreeRetries
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostSpamLimitSpec.
+func (in *GhostSpamLimitSpec) DeepCopy() *GhostSpamLimitSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostSpamLimitSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostSpamSpec) DeepCopyInto(out *GhostSpamSpec) {
+	*out = *in
+	if in.UserLogin != nil {
+		in, out := &in.UserLogin, &out.UserLogin
+		*out = new(GhostSpamLimitSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UserReset != nil {
+		in, out := &in.UserReset, &out.UserReset
+		*out = new(GhostSpamLimitSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GlobalReset != nil {
+		in, out := &in.GlobalReset, &out.GlobalReset
+		*out = new(GhostSpamLimitSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GlobalBlock != nil {
+		in, out := &in.GlobalBlock, &out.GlobalBlock
+		*out = new(GhostSpamLimitSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrivateBlock != nil {
+		in, out := &in.PrivateBlock, &out.PrivateBlock
+		*out = new(GhostSpamLimitSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ContentAPIKey != nil {
+		in, out := &in.ContentAPIKey, &out.ContentAPIKey
+		*out = new(GhostSpamLimitSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostSpamSpec.
+func (in *GhostSpamSpec) DeepCopy() *GhostSpamSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostSpamSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GhostStorageSpec) DeepCopyInto(out *GhostStorageSpec) {
+	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GhostStorageSpec.
+func (in *GhostStorageSpec) DeepCopy() *GhostStorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GhostStorageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -11,9 +11,11 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		"fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostApp":       schema_pkg_apis_ghost_v1alpha1_GhostApp(ref),
-		"fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostAppSpec":   schema_pkg_apis_ghost_v1alpha1_GhostAppSpec(ref),
-		"fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostAppStatus": schema_pkg_apis_ghost_v1alpha1_GhostAppStatus(ref),
+		"fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostApp":          schema_pkg_apis_ghost_v1alpha1_GhostApp(ref),
+		"fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostAppCondition": schema_pkg_apis_ghost_v1alpha1_GhostAppCondition(ref),
+		"fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostAppPodStatus": schema_pkg_apis_ghost_v1alpha1_GhostAppPodStatus(ref),
+		"fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostAppSpec":      schema_pkg_apis_ghost_v1alpha1_GhostAppSpec(ref),
+		"fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostAppStatus":    schema_pkg_apis_ghost_v1alpha1_GhostAppStatus(ref),
 	}
 }
 
@@ -61,6 +63,119 @@ func schema_pkg_apis_ghost_v1alpha1_GhostApp(ref common.ReferenceCallback) commo
 	}
 }
 
+func schema_pkg_apis_ghost_v1alpha1_GhostAppCondition(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GhostAppCondition describes the state of GhostApp at a certain point.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type of GhostApp condition.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Status of the condition, one of True, False, Unknown.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"observedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ObservedGeneration is the GhostApp generation the condition was set based upon.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"lastTransitionTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Last time the condition transitioned from one status to another.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reason is a brief CamelCase reason for the condition's last transition.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message is a human readable message indicating details about the transition.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"type", "status", "lastTransitionTime", "reason", "message"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_ghost_v1alpha1_GhostAppPodStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GhostAppPodStatus describes a problem of a ghost pod container.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the pod.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"container": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Container is the name of the failing container, empty when the pod itself can not be scheduled.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reason is a brief CamelCase reason of the problem, eg: ImagePullBackOff, CrashLoopBackOff, OOMKilled.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message is a human readable message indicating details about the problem.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"restartCount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RestartCount is the number of times the container has been restarted.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"terminationMessage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TerminationMessage is the message the container wrote to /dev/termination-log before it last terminated.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name", "reason"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_ghost_v1alpha1_GhostAppSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -70,7 +185,7 @@ func schema_pkg_apis_ghost_v1alpha1_GhostAppSpec(ref common.ReferenceCallback) c
 				Properties: map[string]spec.Schema{
 					"replicas": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Ghost deployment repicas",
+							Description: "Ghost deployment repicas, defaults to 1.",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
@@ -84,7 +199,7 @@ func schema_pkg_apis_ghost_v1alpha1_GhostAppSpec(ref common.ReferenceCallback) c
 					},
 					"config": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Ghost configuration. This field will be written as ghost configuration. Saved in configmap and mounted in /etc/ghost/config/config.json and symlinked to /var/lib/ghost/config.production.json",
+							Description: "Ghost configuration. This field will be written as ghost configuration. Saved in secret and mounted in /var/lib/ghost/config.production.json",
 							Ref:         ref("fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostConfigSpec"),
 						},
 					},
@@ -93,17 +208,65 @@ func schema_pkg_apis_ghost_v1alpha1_GhostAppSpec(ref common.ReferenceCallback) c
 							Ref: ref("fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostPersistentSpec"),
 						},
 					},
+					"probes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Probes overrides or disables default probes of ghost container.",
+							Ref:         ref("fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostProbesSpec"),
+						},
+					},
+					"security": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Security defines security settings of ghost pods.",
+							Ref:         ref("fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostSecuritySpec"),
+						},
+					},
+					"podTemplate": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodTemplate customizes ghost pods generated by this operator.",
+							Ref:         ref("fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostPodTemplateSpec"),
+						},
+					},
+					"deletionPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeletionPolicy controls what happens to persistent ghost content, including sqlite database, when GhostApp is deleted. One of Delete, Retain, Snapshot, defaults to Delete.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"service": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostServiceSpec"),
+						},
+					},
 					"ingress": {
 						SchemaProps: spec.SchemaProps{
 							Ref: ref("fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostIngressSpec"),
 						},
 					},
+					"gateway": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Gateway exposes ghost through a Gateway API HTTPRoute, as an alternative to ingress.",
+							Ref:         ref("fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostGatewaySpec"),
+						},
+					},
+					"route": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Route exposes ghost through OpenShift Routes, as an alternative to ingress.",
+							Ref:         ref("fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostRouteSpec"),
+						},
+					},
+					"admin": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Admin serves ghost admin panel through a separate ingress on its own host.",
+							Ref:         ref("fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostAppAdminSpec"),
+						},
+					},
 				},
 				Required: []string{"config"},
 			},
 		},
 		Dependencies: []string{
-			"fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostConfigSpec", "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostIngressSpec", "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostPersistentSpec"},
+			"fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostAppAdminSpec", "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostConfigSpec", "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostGatewaySpec", "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostIngressSpec", "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostPersistentSpec", "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostPodTemplateSpec", "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostProbesSpec", "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostRouteSpec", "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostSecuritySpec", "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostServiceSpec"},
 	}
 }
 
@@ -116,8 +279,16 @@ func schema_pkg_apis_ghost_v1alpha1_GhostAppStatus(ref common.ReferenceCallback)
 				Properties: map[string]spec.Schema{
 					"replicas": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "Replicas is the number of ghost pods targeted by ghost deployment.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"readyReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ReadyReplicas is the number of ghost pods ready to serve requests.",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
 					"phase": {
@@ -127,14 +298,87 @@ func schema_pkg_apis_ghost_v1alpha1_GhostAppStatus(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
+					"observedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ObservedGeneration is the most recent GhostApp generation observed by the operator.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"conditions": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": "type",
+								"x-kubernetes-list-type":     "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Conditions of GhostApp.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostAppCondition"),
+									},
+								},
+							},
+						},
+					},
+					"unhealthyPods": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UnhealthyPods lists ghost pod containers that are failing to run.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostAppPodStatus"),
+									},
+								},
+							},
+						},
+					},
 					"reason": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
 							Format: "",
 						},
 					},
+					"routes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Routes reports OpenShift Routes of ghost.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostAppRouteStatus"),
+									},
+								},
+							},
+						},
+					},
+					"certificate": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Certificate reports cert-manager Certificate issued for ingress hosts.",
+							Ref:         ref("fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostAppCertificateStatus"),
+						},
+					},
+					"configHash": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConfigHash is the hash of the ghost configuration applied to the ghost pods.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"acceptedSpec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "AcceptedSpec records spec fields of the last reconciled GhostApp that can not be changed safely.",
+							Ref:         ref("fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostAppAcceptedSpec"),
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostAppAcceptedSpec", "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostAppCertificateStatus", "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostAppCondition", "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostAppPodStatus", "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1.GhostAppRouteStatus"},
 	}
 }
@@ -0,0 +1,211 @@
+// Copyright 2020 Fossil Dev
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ghostapp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// fieldOwner is the server-side apply field manager of every child object of GhostApp.
+const fieldOwner = client.FieldOwner("ghost-operator")
+
+// operationResultDriftCorrected means a child object was changed by someone else while its desired state
+// did not change, and apply has reverted the change.
+const operationResultDriftCorrected controllerutil.OperationResult = "driftCorrected"
+
+// apply makes obj, a child object of cr, match the desired state in obj using server-side apply.
+// Only fields set in obj are owned by this operator, fields set by other controllers, eg: cloud load balancer
+// annotations, are preserved. Fields owned by this operator that were changed by someone else are reverted.
+func (r *ReconcileGhostApp) apply(cr *ghostv1alpha1.GhostApp, obj runtime.Object) (controllerutil.OperationResult, error) {
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	if err := controllerutil.SetControllerReference(cr, objMeta, r.scheme); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	// Apply request requires apiVersion and kind to be set
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	// Hash of the desired state tells a change of the desired state apart from a change made by someone else.
+	desired, err := json.Marshal(obj)
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	hash := sha256.Sum256(desired)
+	appliedHash := hex.EncodeToString(hash[:])
+	objMeta.SetAnnotations(mergeStringMap(objMeta.GetAnnotations(), map[string]string{appliedHashAnnotation: appliedHash}))
+
+	// Unstructured object is read from the API server instead of the cache,
+	// so comparing resource version is not fooled by a stale cache.
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(gvk)
+	key := types.NamespacedName{Name: objMeta.GetName(), Namespace: objMeta.GetNamespace()}
+	err = r.client.Get(context.TODO(), key, existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return controllerutil.OperationResultNone, err
+	}
+
+	if err == nil && !isApplied(existing) {
+		if err := r.takeOver(obj, existing); err != nil {
+			return controllerutil.OperationResultNone, err
+		}
+	}
+
+	if err := r.client.Patch(context.TODO(), obj, client.Apply, fieldOwner, client.ForceOwnership); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	switch existing.GetResourceVersion() {
+	case "":
+		return controllerutil.OperationResultCreated, nil
+	case objMeta.GetResourceVersion():
+		return controllerutil.OperationResultNone, nil
+	}
+
+	if existing.GetAnnotations()[appliedHashAnnotation] == appliedHash {
+		return operationResultDriftCorrected, nil
+	}
+
+	return controllerutil.OperationResultUpdated, nil
+}
+
+// stripManagedFields is a patch that removes every managed fields entry of an object.
+var stripManagedFields = client.ConstantPatch(types.MergePatchType, []byte(`{"metadata":{"managedFields":[{}]}}`))
+
+// isApplied returns true when obj has fields managed by server-side apply of this operator.
+func isApplied(obj metav1.Object) bool {
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager == string(fieldOwner) && entry.Operation == metav1.ManagedFieldsOperationApply {
+			return true
+		}
+	}
+
+	return false
+}
+
+// takeOver migrates existing, a child object created and updated by this operator before it used server-side apply.
+// Fields set by an update are not owned by the apply field manager, so apply never removes them, eg: postStart hook
+// or configMap volume source of legacy ghost deployment. existing is updated to match obj first, then its managed fields
+// are cleared, so the following apply owns every field of obj. Lists in spec are replaced and maps are merged, so
+// fields of spec that obj does not set, eg: clusterIP allocated by the API server, are kept.
+func (r *ReconcileGhostApp) takeOver(obj runtime.Object, existing *unstructured.Unstructured) error {
+	var desired map[string]interface{}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		desired = u.UnstructuredContent()
+	} else {
+		var err error
+		if desired, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj); err != nil {
+			return err
+		}
+	}
+
+	updated := existing.DeepCopy()
+	for key, value := range desired {
+		switch key {
+		case "status":
+			continue
+		case "metadata":
+			metadata, _ := value.(map[string]interface{})
+			for _, field := range []string{"labels", "annotations", "ownerReferences"} {
+				if v, ok := metadata[field]; ok && v != nil {
+					updated.Object["metadata"].(map[string]interface{})[field] = runtime.DeepCopyJSONValue(v)
+				}
+			}
+		case "spec":
+			existingSpec, _ := updated.Object[key].(map[string]interface{})
+			desiredSpec, _ := value.(map[string]interface{})
+			updated.Object[key] = mergeJSON(existingSpec, desiredSpec)
+		default:
+			if value != nil {
+				updated.Object[key] = runtime.DeepCopyJSONValue(value)
+			}
+		}
+	}
+	preserveNodePorts(updated, existing)
+
+	r.logger.Info("Taking over "+existing.GetKind()+" created before server-side apply", existing.GetKind()+".Name", existing.GetName())
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return err
+	}
+
+	return r.client.Patch(context.TODO(), updated, stripManagedFields)
+}
+
+// mergeJSON returns a deep copy of original with maps in patch merged recursively and any other non-null value replaced.
+func mergeJSON(original, patch map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	if original != nil {
+		merged = runtime.DeepCopyJSON(original)
+	}
+	for k, v := range patch {
+		if v == nil {
+			continue
+		}
+		if patchMap, ok := v.(map[string]interface{}); ok {
+			if originalMap, ok := merged[k].(map[string]interface{}); ok {
+				merged[k] = mergeJSON(originalMap, patchMap)
+				continue
+			}
+		}
+		merged[k] = runtime.DeepCopyJSONValue(v)
+	}
+
+	return merged
+}
+
+// preserveNodePorts keeps node ports allocated by the API server to ports of existing service that updated service
+// does not set explicitly, so taking over a NodePort service does not move it to other node ports.
+func preserveNodePorts(updated, existing *unstructured.Unstructured) {
+	if existing.GetKind() != "Service" {
+		return
+	}
+
+	existingPorts, _, _ := unstructured.NestedSlice(existing.Object, "spec", "ports")
+	ports, _, _ := unstructured.NestedSlice(updated.Object, "spec", "ports")
+	for _, p := range ports {
+		port := p.(map[string]interface{})
+		if nodePort, _ := port["nodePort"].(int64); nodePort != 0 {
+			continue
+		}
+		for _, e := range existingPorts {
+			if existingPort := e.(map[string]interface{}); existingPort["port"] == port["port"] && existingPort["nodePort"] != nil {
+				port["nodePort"] = existingPort["nodePort"]
+			}
+		}
+	}
+
+	_ = unstructured.SetNestedSlice(updated.Object, ports, "spec", "ports")
+}
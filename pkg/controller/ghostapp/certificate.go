@@ -0,0 +1,182 @@
+// Copyright 2020 Fossil Dev
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ghostapp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var (
+	certificateV1GVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+	// certificateVersions are cert-manager.io versions in order of preference.
+	// Certificate fields used by this operator are the same in every version.
+	certificateVersions = []string{"v1", "v1beta1", "v1alpha3", "v1alpha2"}
+)
+
+// certificateGVKForMapper returns the latest cert-manager Certificate API version served by the cluster.
+// A NoMatch error is returned when cert-manager is not installed.
+func certificateGVKForMapper(mapper meta.RESTMapper) (schema.GroupVersionKind, error) {
+	mapping, err := mapper.RESTMapping(certificateV1GVK.GroupKind(), certificateVersions...)
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+
+	return mapping.GroupVersionKind, nil
+}
+
+// isCertificateManaged returns true when cr ingress certificate is issued by cert-manager.
+func isCertificateManaged(cr *ghostv1alpha1.GhostApp) bool {
+	return cr.IsIngressEnabled() && cr.Spec.Ingress.TLS.Enabled && cr.Spec.Ingress.TLS.IssuerRef != nil
+}
+
+func certificateNameFromCR(cr *ghostv1alpha1.GhostApp) string {
+	return cr.GetName() + "-tls"
+}
+
+// certificateSecretNameFromCR returns name of the secret holding certificate issued by cert-manager.
+func certificateSecretNameFromCR(cr *ghostv1alpha1.GhostApp) string {
+	if cr.Spec.Ingress.TLS.SecretName != "" {
+		return cr.Spec.Ingress.TLS.SecretName
+	}
+
+	return certificateNameFromCR(cr)
+}
+
+// CreateOrUpdateCertificate requests a cert-manager Certificate for every ingress host of cr.
+// Missing cert-manager is not an error, it is reported by IngressReady condition instead.
+// Certificate without dnsNames is rejected by cert-manager, so cr without ingress hosts is an error.
+func (r *ReconcileGhostApp) CreateOrUpdateCertificate(cr *ghostv1alpha1.GhostApp) error {
+	hosts := cr.IngressHosts()
+	if len(hosts) == 0 {
+		return fmt.Errorf("can not request Certificate %s without ingress hosts", certificateNameFromCR(cr))
+	}
+
+	if r.certificateGVK.Empty() {
+		r.logger.Info("Certificate API is not served, cert-manager is not installed")
+		return nil
+	}
+
+	issuerRef := cr.Spec.Ingress.TLS.IssuerRef
+	cert := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"secretName": certificateSecretNameFromCR(cr),
+			"dnsNames":   stringsToInterfaces(hosts),
+			"issuerRef": map[string]interface{}{
+				"name":  issuerRef.Name,
+				"kind":  issuerRef.Kind,
+				"group": issuerRef.Group,
+			},
+		},
+	}}
+	cert.SetGroupVersionKind(r.certificateGVK)
+	cert.SetName(certificateNameFromCR(cr))
+	cert.SetNamespace(cr.GetNamespace())
+	cert.SetLabels(commonLabelFromCR(cr))
+
+	op, err := r.apply(cr, cert)
+	r.logger.Info("Reconciling Certificate", "Operation.Result", op)
+	r.recordOperation(cr, "Certificate", cert.GetName(), op)
+	return err
+}
+
+// DeleteCertificate deletes certificate of cr that is no longer desired. The issued secret is left to cert-manager.
+// Clusters without cert-manager are not queried, since every request of an unknown kind reloads API discovery.
+func (r *ReconcileGhostApp) DeleteCertificate(cr *ghostv1alpha1.GhostApp) error {
+	if r.certificateGVK.Empty() {
+		return nil
+	}
+
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(r.certificateGVK)
+	return r.deleteOwnedObject(cr, "Certificate", certificateNameFromCR(cr), cert)
+}
+
+// updateCertificateStatus reports certificate of cr in its status and returns true once the certificate is ready.
+// IngressReady condition is set to false while the certificate is not ready.
+func (r *ReconcileGhostApp) updateCertificateStatus(cr *ghostv1alpha1.GhostApp) (bool, error) {
+	name := certificateNameFromCR(cr)
+	if r.certificateGVK.Empty() {
+		cr.Status.Certificate = nil
+		r.setWarningCondition(cr, ghostv1alpha1.GhostAppConditionIngressReady, "CertManagerNotInstalled",
+			fmt.Sprintf("cert-manager is not installed, Certificate %s can not be requested", name))
+		return false, nil
+	}
+
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(r.certificateGVK)
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: cr.GetNamespace()}, cert)
+	if errors.IsNotFound(err) {
+		cr.Status.Certificate = nil
+		cr.SetCondition(ghostv1alpha1.GhostAppConditionIngressReady, corev1.ConditionFalse, "CertificatePending",
+			fmt.Sprintf("Certificate %s is not created yet", name))
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	status := &ghostv1alpha1.GhostAppCertificateStatus{
+		Name:        name,
+		NotAfter:    nestedTime(cert.Object, "status", "notAfter"),
+		RenewalTime: nestedTime(cert.Object, "status", "renewalTime"),
+	}
+	conditions, _, _ := unstructured.NestedSlice(cert.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		status.Ready = condition["status"] == string(corev1.ConditionTrue)
+		status.Reason, _ = condition["reason"].(string)
+		status.Message, _ = condition["message"].(string)
+	}
+	cr.Status.Certificate = status
+
+	if !status.Ready {
+		message := fmt.Sprintf("Certificate %s is not ready", name)
+		if status.Message != "" {
+			message += ": " + status.Message
+		}
+		cr.SetCondition(ghostv1alpha1.GhostAppConditionIngressReady, corev1.ConditionFalse, "CertificateNotReady", message)
+	}
+
+	return status.Ready, nil
+}
+
+// nestedTime returns RFC3339 time at fields of obj, or nil when it is not found.
+func nestedTime(obj map[string]interface{}, fields ...string) *metav1.Time {
+	value, found, _ := unstructured.NestedString(obj, fields...)
+	if !found {
+		return nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+
+	return &metav1.Time{Time: t}
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 Fossil Dev
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ghostapp
+
+import (
+	"context"
+
+	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DeleteIngress deletes ingress of cr that is no longer desired, so it stops routing traffic to ghost.
+func (r *ReconcileGhostApp) DeleteIngress(cr *ghostv1alpha1.GhostApp) error {
+	ing := &unstructured.Unstructured{}
+	ing.SetGroupVersionKind(r.ingressKind())
+	return r.deleteOwnedObject(cr, "Ingress", cr.GetName(), ing)
+}
+
+// DeleteAdminIngress deletes admin ingress of cr that is no longer desired, so it stops serving ghost admin panel.
+func (r *ReconcileGhostApp) DeleteAdminIngress(cr *ghostv1alpha1.GhostApp) error {
+	ing := &unstructured.Unstructured{}
+	ing.SetGroupVersionKind(r.ingressKind())
+	return r.deleteOwnedObject(cr, "Ingress", adminIngressNameFromCR(cr), ing)
+}
+
+// DeletePersistentVolumeClaim removes persistentVolumeClaim of cr that is no longer desired. GhostApp is still alive,
+// so the persistentVolumeClaim is retained instead unless persistent.deleteWhenDisabled is set, ghost content is never
+// lost by disabling persistent. Deletion policy only applies when GhostApp is deleted.
+func (r *ReconcileGhostApp) DeletePersistentVolumeClaim(cr *ghostv1alpha1.GhostApp) error {
+	if !cr.Spec.Persistent.DeleteWhenDisabled {
+		return r.retainPersistentVolumeClaim(cr)
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.deleteOwnedObject(cr, "PersistentVolumeClaim", persistentVolumeClaimNameFromCR(cr), pvc); err != nil {
+		return err
+	}
+
+	// pvc is left as read before deletion, it is only deleted when controlled by cr and not being deleted yet.
+	if metav1.IsControlledBy(pvc, cr) && pvc.GetDeletionTimestamp() == nil {
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, "ContentDeleted",
+			"Deleted PersistentVolumeClaim %s of disabled persistent, ghost content stored in it is lost", pvc.GetName())
+	}
+	return nil
+}
+
+// deleteOwnedObject deletes object of kind with the given name when it exists and is controlled by cr.
+func (r *ReconcileGhostApp) deleteOwnedObject(cr *ghostv1alpha1.GhostApp, kind, name string, obj runtime.Object) error {
+	key := types.NamespacedName{Name: name, Namespace: cr.GetNamespace()}
+	if err := r.client.Get(context.TODO(), key, obj); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	objMeta, ok := obj.(metav1.Object)
+	if !ok || !metav1.IsControlledBy(objMeta, cr) || objMeta.GetDeletionTimestamp() != nil {
+		return nil
+	}
+
+	r.logger.Info("Deleting "+kind, kind+".Name", name)
+	if err := r.client.Delete(context.TODO(), obj); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	r.recorder.Eventf(cr, corev1.EventTypeNormal, "Deleted", "Deleted %s %s", kind, name)
+	return nil
+}
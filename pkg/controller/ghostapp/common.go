@@ -17,8 +17,16 @@ package ghostapp
 import (
 	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+const configHashAnnotation = "ghost.fossil.or.id/config-hash"
+
+// appliedHashAnnotation is set on every child object of GhostApp to the hash of its last applied desired state.
+const appliedHashAnnotation = "ghost.fossil.or.id/applied-hash"
+
 func commonLabelFromCR(cr *ghostv1alpha1.GhostApp) map[string]string {
 	return map[string]string{
 		"app.kubernetes.io/name":     "ghostapp",
@@ -32,7 +40,29 @@ func commonLabelSelectorFromCR(cr *ghostv1alpha1.GhostApp) *metav1.LabelSelector
 	}
 }
 
-func configMapNameFromCR(cr *ghostv1alpha1.GhostApp) string { return cr.GetName() + "-ghost-config" }
+// commonLabelToGhostApp maps an object labeled with commonLabelFromCR, eg: ghost pod or endpoints of ghost service
+// that inherit labels of the service, to reconcile request of the GhostApp it belongs to. Any other object is ignored.
+var commonLabelToGhostApp = handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+	labels := obj.Meta.GetLabels()
+	if labels["app.kubernetes.io/name"] != "ghostapp" || labels["app.kubernetes.io/instance"] == "" {
+		return nil
+	}
+
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{Name: labels["app.kubernetes.io/instance"], Namespace: obj.Meta.GetNamespace()},
+	}}
+})
+
+func configSecretNameFromCR(cr *ghostv1alpha1.GhostApp) string {
+	return cr.GetName() + "-ghost-config"
+}
+
+// legacyConfigMapNameFromCR returns name of configmap used to hold ghost configuration before it was moved into secret.
+// The secret took over the name of the configmap.
+func legacyConfigMapNameFromCR(cr *ghostv1alpha1.GhostApp) string {
+	return configSecretNameFromCR(cr)
+}
+
 func persistentVolumeClaimNameFromCR(cr *ghostv1alpha1.GhostApp) string {
 	return cr.GetName() + "-ghost-content-pvc"
 }
@@ -0,0 +1,179 @@
+// Copyright 2019 Fossil Dev
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ghostapp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ghostConfigFileName is the file name ghost reads its configuration from when NODE_ENV is production.
+const ghostConfigFileName = "config.production.json"
+
+// CreateOrUpdateConfigSecret renders ghost configuration into a secret, since the rendered configuration
+// may contain credentials. The secret is mounted directly at /var/lib/ghost/config.production.json.
+func (r *ReconcileGhostApp) CreateOrUpdateConfigSecret(cr *ghostv1alpha1.GhostApp) error {
+	config, err := ghostConfigFromCR(cr)
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configSecretNameFromCR(cr),
+			Namespace: cr.GetNamespace(),
+			Labels:    commonLabelFromCR(cr),
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			ghostConfigFileName: config,
+		},
+	}
+
+	op, err := r.apply(cr, secret)
+
+	r.logger.Info("Reconciling Config Secret", "Operation.Result", op)
+	r.recordOperation(cr, "Secret", secret.GetName(), op)
+	if err != nil {
+		return err
+	}
+
+	return r.deleteLegacyConfigMap(cr)
+}
+
+// deleteLegacyConfigMap deletes configmap that was used to hold ghost configuration by previous version of
+// this operator, so credentials written in plaintext do not outlive the upgrade.
+func (r *ReconcileGhostApp) deleteLegacyConfigMap(cr *ghostv1alpha1.GhostApp) error {
+	cm := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: legacyConfigMapNameFromCR(cr), Namespace: cr.GetNamespace()}
+	if err := r.client.Get(context.TODO(), key, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if !metav1.IsControlledBy(cm, cr) {
+		return nil
+	}
+
+	r.logger.Info("Deleting legacy ConfigMap", "ConfigMap.Name", cm.GetName())
+	if err := r.client.Delete(context.TODO(), cm); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	r.recorder.Eventf(cr, corev1.EventTypeNormal, "Deleted", "Deleted legacy ConfigMap %s", cm.GetName())
+
+	return nil
+}
+
+// configHashForCR returns a hash of the effective ghost configuration, that is the rendered config
+// and every value referenced from secrets. Any effective configuration change results in a new hash.
+func (r *ReconcileGhostApp) configHashForCR(cr *ghostv1alpha1.GhostApp) (string, error) {
+	config, err := ghostConfigFromCR(cr)
+	if err != nil {
+		return "", err
+	}
+
+	secretsHash, err := r.secretsHashForCR(cr)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	hash.Write(config)
+	hash.Write([]byte(secretsHash))
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// ghostConfigFromCR renders ghost configuration from cr.
+// Values referenced from secrets are injected to ghost container as environment variables,
+// so neither the secret reference nor the plaintext value is written into the config.
+func ghostConfigFromCR(cr *ghostv1alpha1.GhostApp) ([]byte, error) {
+	config := cr.Spec.Config.DeepCopy()
+	if adminURL := cr.AdminURL(); adminURL != "" {
+		config.Admin = &ghostv1alpha1.GhostAdminSpec{URL: adminURL}
+	}
+	conn := &config.Database.Connection
+	if conn.UserSecretRef != nil {
+		conn.User = ""
+		conn.UserSecretRef = nil
+	}
+	if conn.PasswordSecretRef != nil {
+		conn.Password = ""
+		conn.PasswordSecretRef = nil
+	}
+	if config.Mail != nil && config.Mail.Options != nil {
+		config.Mail.Options.Auth = nil
+	}
+
+	extra := config.Extra
+	config.Extra = nil
+
+	var storageOptions interface{}
+	if config.Storage != nil && config.Storage.Options != nil {
+		if err := json.Unmarshal(config.Storage.Options.Raw, &storageOptions); err != nil {
+			return nil, fmt.Errorf("invalid spec.config.storage.options: %v", err)
+		}
+		config.Storage.Options = nil
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := make(map[string]interface{})
+	if err := json.Unmarshal(data, &rendered); err != nil {
+		return nil, err
+	}
+
+	// ghost reads options of storage adapter from storage.<active>.
+	if storageOptions != nil && config.Storage.Active != "" {
+		rendered["storage"].(map[string]interface{})[config.Storage.Active] = storageOptions
+	}
+
+	if extra != nil && len(extra.Raw) > 0 {
+		extraConfig := make(map[string]interface{})
+		if err := json.Unmarshal(extra.Raw, &extraConfig); err != nil {
+			return nil, fmt.Errorf("invalid spec.config.extra, it must be a JSON object: %v", err)
+		}
+		mergeConfig(rendered, extraConfig)
+	}
+
+	return json.MarshalIndent(rendered, "", "  ")
+}
+
+// mergeConfig deep-merges src into dst. Nested objects are merged recursively,
+// any other value in src replaces the value in dst.
+func mergeConfig(dst, src map[string]interface{}) {
+	for key, srcValue := range src {
+		srcMap, srcIsMap := srcValue.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeConfig(dstMap, srcMap)
+			continue
+		}
+		dst[key] = srcValue
+	}
+}
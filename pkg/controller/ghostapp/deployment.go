@@ -15,16 +15,18 @@
 package ghostapp
 
 import (
-	"context"
-
 	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 func (r *ReconcileGhostApp) CreateOrUpdateDeployment(cr *ghostv1alpha1.GhostApp) error {
+	configHash, err := r.configHashForCR(cr)
+	if err != nil {
+		return err
+	}
+
 	defaultTerminationGracePeriodSeconds := int64(30)
 	dep := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -32,74 +34,78 @@ func (r *ReconcileGhostApp) CreateOrUpdateDeployment(cr *ghostv1alpha1.GhostApp)
 			Namespace: cr.GetNamespace(),
 			Labels:    commonLabelFromCR(cr),
 		},
-	}
-
-	op, err := controllerutil.CreateOrUpdate(context.TODO(), r.client, dep, func() error {
-		if dep.ObjectMeta.CreationTimestamp.IsZero() {
-			// Set label selector only when deployment has never been created
-			dep.Spec.Selector = commonLabelSelectorFromCR(cr)
-		}
-
-		if err := controllerutil.SetControllerReference(cr, dep, r.scheme); err != nil {
-			return err
-		}
-
-		dep.Spec.Replicas = cr.Spec.Replicas
-		dep.Spec.Template = corev1.PodTemplateSpec{
-			ObjectMeta: metav1.ObjectMeta{
-				Labels: commonLabelFromCR(cr),
-			},
-			Spec: corev1.PodSpec{
-				Containers: []corev1.Container{
-					{
-						Name:            "ghost",
-						Image:           cr.Spec.Image,
-						ImagePullPolicy: corev1.PullIfNotPresent,
-						Ports: []corev1.ContainerPort{
-							{
-								Name:          "http",
-								ContainerPort: int32(2368),
-								Protocol:      corev1.ProtocolTCP,
-							},
-						},
-						Lifecycle: &corev1.Lifecycle{
-							PostStart: &corev1.Handler{
-								Exec: &corev1.ExecAction{
-									Command: []string{"/bin/sh", "-c", "ln -sf /etc/ghost/config/config.json /var/lib/ghost/config.production.json"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: cr.Spec.Replicas,
+			Selector: commonLabelSelectorFromCR(cr),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: commonLabelFromCR(cr),
+					// Changing this annotation rolls ghost pods whenever the effective configuration changes.
+					Annotations: map[string]string{
+						configHashAnnotation: configHash,
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:            "ghost",
+							Image:           cr.Spec.Image,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          "http",
+									ContainerPort: int32(cr.Spec.Config.Server.Port.IntValue()),
+									Protocol:      corev1.ProtocolTCP,
 								},
 							},
+							Env:                      secretEnvFromCR(cr),
+							StartupProbe:             startupProbeForCR(cr),
+							ReadinessProbe:           readinessProbeForCR(cr),
+							LivenessProbe:            livenessProbeForCR(cr),
+							SecurityContext:          containerSecurityContextForCR(cr),
+							TerminationMessagePath:   "/dev/termination-log",
+							TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+							VolumeMounts:             r.newVolumeMountForCR(cr),
 						},
-						TerminationMessagePath:   "/dev/termination-log",
-						TerminationMessagePolicy: corev1.TerminationMessageReadFile,
-						VolumeMounts:             r.newVolumeMountForCR(cr),
 					},
+					RestartPolicy:                 corev1.RestartPolicyAlways,
+					TerminationGracePeriodSeconds: &defaultTerminationGracePeriodSeconds,
+					DNSPolicy:                     corev1.DNSClusterFirst,
+					SecurityContext:               podSecurityContextForCR(cr),
+					SchedulerName:                 corev1.DefaultSchedulerName,
+					Volumes:                       r.newVolumeForCR(cr),
 				},
-				RestartPolicy:                 corev1.RestartPolicyAlways,
-				TerminationGracePeriodSeconds: &defaultTerminationGracePeriodSeconds,
-				DNSPolicy:                     corev1.DNSClusterFirst,
-				SecurityContext:               &corev1.PodSecurityContext{},
-				SchedulerName:                 corev1.DefaultSchedulerName,
-				Volumes:                       r.newVolumeForCR(cr),
 			},
-		}
-		return nil
-	})
+		},
+	}
+
+	mergePodTemplate(&dep.Spec.Template, cr.Spec.PodTemplate)
+
+	obj, err := withRuntimeDefaultSeccompProfile(dep)
+	if err != nil {
+		return err
+	}
 
+	op, err := r.apply(cr, obj)
 	r.logger.Info("Reconciling Deployment", "Operation.Result", op)
-	return err
+	r.recordOperation(cr, "Deployment", dep.GetName(), op)
+	if err != nil {
+		return err
+	}
+
+	cr.Status.ConfigHash = configHash
+	return nil
 }
 
 func (r *ReconcileGhostApp) newVolumeForCR(cr *ghostv1alpha1.GhostApp) []corev1.Volume {
-	configMapDefaultMode := int32(0644)
+	configSecretDefaultMode := int32(0644)
 	var volume []corev1.Volume
 	volume = append(volume, corev1.Volume{
 		Name: "ghost-config",
 		VolumeSource: corev1.VolumeSource{
-			ConfigMap: &corev1.ConfigMapVolumeSource{
-				LocalObjectReference: corev1.LocalObjectReference{
-					Name: configMapNameFromCR(cr),
-				},
-				DefaultMode: &configMapDefaultMode,
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  configSecretNameFromCR(cr),
+				DefaultMode: &configSecretDefaultMode,
 			},
 		},
 	})
@@ -122,16 +128,27 @@ func (r *ReconcileGhostApp) newVolumeForCR(cr *ghostv1alpha1.GhostApp) []corev1.
 		VolumeSource: ghostContentSource,
 	})
 
+	if cr.Spec.Security.ReadOnlyRootFilesystem {
+		volume = append(volume, corev1.Volume{
+			Name: "ghost-tmp",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+	}
+
 	return volume
 }
 
 func (r *ReconcileGhostApp) newVolumeMountForCR(cr *ghostv1alpha1.GhostApp) []corev1.VolumeMount {
 	var volumeMount []corev1.VolumeMount
 
+	// Mount config file with subPath, so ghost reads the rendered config on the first process start.
 	volumeMount = append(volumeMount, corev1.VolumeMount{
 		Name:      "ghost-config",
 		ReadOnly:  true,
-		MountPath: "/etc/ghost/config",
+		MountPath: "/var/lib/ghost/" + ghostConfigFileName,
+		SubPath:   ghostConfigFileName,
 	})
 	volumeMount = append(volumeMount, corev1.VolumeMount{
 		Name:      "ghost-content",
@@ -139,6 +156,73 @@ func (r *ReconcileGhostApp) newVolumeMountForCR(cr *ghostv1alpha1.GhostApp) []co
 		MountPath: "/var/lib/ghost/content",
 	})
 
+	// Ghost writes uploaded files to /tmp before moving them into content directory.
+	if cr.Spec.Security.ReadOnlyRootFilesystem {
+		volumeMount = append(volumeMount, corev1.VolumeMount{
+			Name:      "ghost-tmp",
+			MountPath: "/tmp",
+		})
+	}
+
 	return volumeMount
 
 }
+
+// mergePodTemplate merges user supplied pod customization into the generated ghost pod template.
+// Labels and annotations generated by this operator can not be overridden, since they select ghost pods
+// and roll them on configuration change. Init containers and sidecars without their own security context run
+// with the restricted security context.
+func mergePodTemplate(template *corev1.PodTemplateSpec, custom ghostv1alpha1.GhostPodTemplateSpec) {
+	template.Labels = mergeStringMap(custom.Labels, template.Labels)
+	template.Annotations = mergeStringMap(custom.Annotations, template.Annotations)
+
+	spec := &template.Spec
+	spec.NodeSelector = custom.NodeSelector
+	spec.Tolerations = custom.Tolerations
+	spec.Affinity = custom.Affinity
+	spec.TopologySpreadConstraints = custom.TopologySpreadConstraints
+	spec.PriorityClassName = custom.PriorityClassName
+	spec.ServiceAccountName = custom.ServiceAccountName
+	spec.ImagePullSecrets = custom.ImagePullSecrets
+	spec.Volumes = append(spec.Volumes, custom.Volumes...)
+	spec.InitContainers = restrictContainers(custom.InitContainers)
+	spec.Containers = append(spec.Containers, restrictContainers(custom.Sidecars)...)
+
+	ghost := &spec.Containers[0]
+	ghost.Resources = custom.Resources
+	ghost.Env = mergeEnv(ghost.Env, custom.Env)
+	ghost.EnvFrom = custom.EnvFrom
+	ghost.VolumeMounts = append(ghost.VolumeMounts, custom.VolumeMounts...)
+}
+
+// mergeStringMap returns a new map holding entries of all given maps, later maps take precedence.
+func mergeStringMap(maps ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, m := range maps {
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+
+	return merged
+}
+
+// mergeEnv returns env with variables in custom appended, variables in custom with the same name replace
+// variables in env, so every variable name appears once.
+func mergeEnv(env, custom []corev1.EnvVar) []corev1.EnvVar {
+	merged := make([]corev1.EnvVar, 0, len(env)+len(custom))
+	for _, e := range env {
+		overridden := false
+		for _, c := range custom {
+			if c.Name == e.Name {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			merged = append(merged, e)
+		}
+	}
+
+	return append(merged, custom...)
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 Fossil Dev
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ghostapp
+
+import (
+	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// recordOperation records a normal event on cr when a child object of kind has been created or updated.
+// Unchanged objects are not recorded, so steady state reconciles do not produce any event.
+func (r *ReconcileGhostApp) recordOperation(cr *ghostv1alpha1.GhostApp, kind, name string, op controllerutil.OperationResult) {
+	switch op {
+	case controllerutil.OperationResultCreated:
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, "Created", "Created %s %s", kind, name)
+	case controllerutil.OperationResultUpdated:
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, "Updated", "Updated %s %s", kind, name)
+	case operationResultDriftCorrected:
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, "DriftCorrected", "Reverted changes to %s %s", kind, name)
+	}
+}
+
+// recordFailure records a warning event on cr for err, unless the same failure is already reported by
+// conditionType condition of cr, so a failure retried with backoff is only recorded once.
+func (r *ReconcileGhostApp) recordFailure(cr *ghostv1alpha1.GhostApp, conditionType ghostv1alpha1.GhostAppConditionType, reason string, err error) {
+	condition := cr.GetCondition(conditionType)
+	if condition != nil && condition.Status == corev1.ConditionFalse && condition.Reason == reason && condition.Message == err.Error() {
+		return
+	}
+
+	r.recorder.Event(cr, corev1.EventTypeWarning, reason, err.Error())
+}
+
+// setWarningCondition sets conditionType condition of cr false with reason that needs attention of the user,
+// eg: an optional API is not served by the cluster. A warning event is recorded only when the condition does not
+// report the reason yet.
+func (r *ReconcileGhostApp) setWarningCondition(cr *ghostv1alpha1.GhostApp, conditionType ghostv1alpha1.GhostAppConditionType, reason, message string) {
+	if condition := cr.GetCondition(conditionType); condition == nil || condition.Reason != reason {
+		r.recorder.Event(cr, corev1.EventTypeWarning, reason, message)
+	}
+	cr.SetCondition(conditionType, corev1.ConditionFalse, reason, message)
+}
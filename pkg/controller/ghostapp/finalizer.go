@@ -0,0 +1,257 @@
+// Copyright 2020 Fossil Dev
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ghostapp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// ghostAppFinalizer blocks GhostApp deletion until its deletion policy is applied.
+	ghostAppFinalizer = "ghost.fossil.or.id/finalizer"
+
+	// retainedFromLabel is set on persistentVolumeClaim orphaned by Retain deletion policy.
+	// Its value is the name of GhostApp that used it.
+	retainedFromLabel = "ghost.fossil.or.id/retained-from"
+
+	// snapshotRequeueInterval is the interval to requeue deleted GhostApp while its content snapshot is not ready.
+	snapshotRequeueInterval = 10 * time.Second
+)
+
+var volumeSnapshotGVK = schema.GroupVersionKind{
+	Group:   "snapshot.storage.k8s.io",
+	Version: "v1",
+	Kind:    "VolumeSnapshot",
+}
+
+// addFinalizer adds ghostAppFinalizer to cr if it does not exist yet.
+func (r *ReconcileGhostApp) addFinalizer(cr *ghostv1alpha1.GhostApp) error {
+	if containsString(cr.GetFinalizers(), ghostAppFinalizer) {
+		return nil
+	}
+
+	cr.SetFinalizers(append(cr.GetFinalizers(), ghostAppFinalizer))
+	return r.client.Update(context.TODO(), cr)
+}
+
+// reconcileDelete applies deletion policy of cr, then removes ghostAppFinalizer, so cr can be deleted.
+func (r *ReconcileGhostApp) reconcileDelete(cr *ghostv1alpha1.GhostApp) (reconcile.Result, error) {
+	if !containsString(cr.GetFinalizers(), ghostAppFinalizer) {
+		return reconcile.Result{}, nil
+	}
+
+	done, err := r.finalize(cr)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !done {
+		return reconcile.Result{RequeueAfter: snapshotRequeueInterval}, nil
+	}
+
+	cr.SetFinalizers(removeString(cr.GetFinalizers(), ghostAppFinalizer))
+	return reconcile.Result{}, r.client.Update(context.TODO(), cr)
+}
+
+// finalize applies deletion policy of cr to its persistentVolumeClaim, including persistentVolumeClaim retained
+// when persistent of cr was disabled. It returns false while cr has to wait for the policy to be applied.
+func (r *ReconcileGhostApp) finalize(cr *ghostv1alpha1.GhostApp) (bool, error) {
+	if !cr.IsPersistentEnabled() {
+		pvc, err := r.getRetainedPersistentVolumeClaim(cr)
+		if err != nil || pvc == nil {
+			return true, err
+		}
+	}
+
+	switch cr.Spec.DeletionPolicy {
+	case ghostv1alpha1.GhostAppDeletionPolicyRetain:
+		return true, r.retainPersistentVolumeClaim(cr)
+	case ghostv1alpha1.GhostAppDeletionPolicySnapshot:
+		return r.snapshotPersistentVolumeClaim(cr)
+	default:
+		// PersistentVolumeClaim is garbage collected along with its owner GhostApp
+		return true, r.deleteRetainedPersistentVolumeClaim(cr)
+	}
+}
+
+// getRetainedPersistentVolumeClaim returns persistentVolumeClaim retained from cr, which is not owned by cr anymore,
+// or nil when there is none.
+func (r *ReconcileGhostApp) getRetainedPersistentVolumeClaim(cr *ghostv1alpha1.GhostApp) (*corev1.PersistentVolumeClaim, error) {
+	pvc := &corev1.PersistentVolumeClaim{}
+	key := types.NamespacedName{Name: persistentVolumeClaimNameFromCR(cr), Namespace: cr.GetNamespace()}
+	if err := r.client.Get(context.TODO(), key, pvc); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if pvc.GetLabels()[retainedFromLabel] != cr.GetName() || metav1.IsControlledBy(pvc, cr) {
+		return nil, nil
+	}
+
+	return pvc, nil
+}
+
+// deleteRetainedPersistentVolumeClaim deletes persistentVolumeClaim retained from cr, since it is not garbage collected
+// along with cr.
+func (r *ReconcileGhostApp) deleteRetainedPersistentVolumeClaim(cr *ghostv1alpha1.GhostApp) error {
+	pvc, err := r.getRetainedPersistentVolumeClaim(cr)
+	if err != nil || pvc == nil {
+		return err
+	}
+
+	if err := r.client.Delete(context.TODO(), pvc); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	r.recorder.Eventf(cr, corev1.EventTypeNormal, "Deleted", "Deleted retained PersistentVolumeClaim %s", pvc.GetName())
+	return nil
+}
+
+// retainPersistentVolumeClaim removes owner reference of cr from its persistentVolumeClaim, so it is not garbage
+// collected. GhostApp created later with the same name adopts the persistentVolumeClaim again.
+func (r *ReconcileGhostApp) retainPersistentVolumeClaim(cr *ghostv1alpha1.GhostApp) error {
+	pvc := &corev1.PersistentVolumeClaim{}
+	key := types.NamespacedName{Name: persistentVolumeClaimNameFromCR(cr), Namespace: cr.GetNamespace()}
+	if err := r.client.Get(context.TODO(), key, pvc); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if !metav1.IsControlledBy(pvc, cr) {
+		return nil
+	}
+
+	var ownerReferences []metav1.OwnerReference
+	for _, ref := range pvc.GetOwnerReferences() {
+		if ref.UID != cr.GetUID() {
+			ownerReferences = append(ownerReferences, ref)
+		}
+	}
+	pvc.SetOwnerReferences(ownerReferences)
+
+	labels := pvc.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[retainedFromLabel] = cr.GetName()
+	pvc.SetLabels(labels)
+
+	if err := r.client.Update(context.TODO(), pvc); err != nil {
+		return err
+	}
+
+	r.recorder.Eventf(cr, corev1.EventTypeNormal, "Retained", "Retained PersistentVolumeClaim %s", pvc.GetName())
+	return nil
+}
+
+// snapshotPersistentVolumeClaim takes a VolumeSnapshot of cr persistentVolumeClaim and returns true once it is ready
+// to use. PersistentVolumeClaim is retained when the snapshot can not be taken, so ghost content is never lost.
+func (r *ReconcileGhostApp) snapshotPersistentVolumeClaim(cr *ghostv1alpha1.GhostApp) (bool, error) {
+	pvcName := persistentVolumeClaimNameFromCR(cr)
+	// Deletion timestamp makes the name unique for every GhostApp with the same name
+	name := fmt.Sprintf("%s-%d", pvcName, cr.GetDeletionTimestamp().Unix())
+
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: cr.GetNamespace()}, snapshot)
+	if meta.IsNoMatchError(err) {
+		r.recorder.Event(cr, corev1.EventTypeWarning, "SnapshotUnsupported",
+			"VolumeSnapshot is not supported by the cluster, retaining PersistentVolumeClaim instead")
+		return true, r.retainPersistentVolumeClaim(cr)
+	}
+
+	if errors.IsNotFound(err) {
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Name: pvcName, Namespace: cr.GetNamespace()}, pvc); err != nil {
+			if errors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		}
+
+		snapshot.SetName(name)
+		snapshot.SetNamespace(cr.GetNamespace())
+		snapshot.SetLabels(commonLabelFromCR(cr))
+		spec := map[string]interface{}{
+			"source": map[string]interface{}{
+				"persistentVolumeClaimName": pvcName,
+			},
+		}
+		if cr.Spec.Persistent.VolumeSnapshotClassName != nil {
+			spec["volumeSnapshotClassName"] = *cr.Spec.Persistent.VolumeSnapshotClassName
+		}
+		snapshot.Object["spec"] = spec
+
+		if err := r.client.Create(context.TODO(), snapshot); err != nil {
+			return false, err
+		}
+
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, "Created", "Created VolumeSnapshot %s", name)
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse"); ready {
+		return true, r.deleteRetainedPersistentVolumeClaim(cr)
+	}
+
+	if message, found, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); found {
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, "SnapshotFailed",
+			"VolumeSnapshot %s failed, retaining PersistentVolumeClaim instead: %s", name, message)
+		return true, r.retainPersistentVolumeClaim(cr)
+	}
+
+	return false, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func removeString(list []string, s string) []string {
+	var result []string
+	for _, item := range list {
+		if item != s {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
@@ -16,13 +16,18 @@ package ghostapp
 
 import (
 	"context"
+	"reflect"
 
 	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -37,16 +42,50 @@ var log = logf.Log.WithName("controller_ghostapp")
 // Add creates a new GhostApp Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+	ingressGVK, err := ingressGVKForMapper(mgr.GetRESTMapper())
+	if err != nil {
+		return err
+	}
+
+	// cert-manager is optional, installing it later requires restarting the operator.
+	certificateGVK, err := certificateGVKForMapper(mgr.GetRESTMapper())
+	if err != nil && !meta.IsNoMatchError(err) {
+		return err
+	}
+
+	// Gateway API is optional, installing it later requires restarting the operator.
+	httpRouteGVK, err := httpRouteGVKForMapper(mgr.GetRESTMapper())
+	if err != nil && !meta.IsNoMatchError(err) {
+		return err
+	}
+
+	// OpenShift Route API is only served by OpenShift clusters.
+	routeGVK, err := routeGVKForMapper(mgr.GetRESTMapper())
+	if err != nil && !meta.IsNoMatchError(err) {
+		return err
+	}
+
+	r := newReconciler(mgr, ingressGVK, certificateGVK, httpRouteGVK, routeGVK)
+	return add(mgr, r, ingressGVK, certificateGVK, httpRouteGVK, routeGVK)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileGhostApp{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+func newReconciler(mgr manager.Manager, ingressGVK, certificateGVK, httpRouteGVK, routeGVK schema.GroupVersionKind) reconcile.Reconciler {
+	return &ReconcileGhostApp{
+		client:         mgr.GetClient(),
+		scheme:         mgr.GetScheme(),
+		recorder:       mgr.GetEventRecorderFor("ghostapp-controller"),
+		ingressGVK:     ingressGVK,
+		certificateGVK: certificateGVK,
+		httpRouteGVK:   httpRouteGVK,
+		routeGVK:       routeGVK,
+	}
 }
 
-// add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler) error {
+// add adds a new Controller to mgr with r as the reconcile.Reconciler.
+// certificateGVK, httpRouteGVK and routeGVK are empty when cert-manager, Gateway API and OpenShift Route API
+// are not served.
+func add(mgr manager.Manager, r reconcile.Reconciler, ingressGVK, certificateGVK, httpRouteGVK, routeGVK schema.GroupVersionKind) error {
 	// Create a new controller
 	c, err := controller.New("ghostapp-controller", mgr, controller.Options{Reconciler: r})
 	if err != nil {
@@ -63,8 +102,15 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		OwnerType:    &ghostv1alpha1.GhostApp{},
 	}
 
-	// Watch for changes to ConfigMap and requeue the owner GhostApp
-	if err := c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, owner); err != nil {
+	// Watch for changes to Secret and requeue the owner GhostApp
+	if err := c.Watch(&source.Kind{Type: &corev1.Secret{}}, owner); err != nil {
+		return err
+	}
+
+	// Watch for changes to Secret and requeue GhostApp referencing it
+	if err := c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: &secretToGhostApps{client: mgr.GetClient()},
+	}); err != nil {
 		return err
 	}
 
@@ -83,6 +129,55 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for changes to Ingress in the version served by the cluster and requeue the owner GhostApp
+	ing := &unstructured.Unstructured{}
+	ing.SetGroupVersionKind(ingressGVK)
+	if err := c.Watch(&source.Kind{Type: ing}, owner); err != nil {
+		return err
+	}
+
+	// Watch for changes to cert-manager Certificate in the version served by the cluster and requeue the owner GhostApp
+	if !certificateGVK.Empty() {
+		cert := &unstructured.Unstructured{}
+		cert.SetGroupVersionKind(certificateGVK)
+		if err := c.Watch(&source.Kind{Type: cert}, owner); err != nil {
+			return err
+		}
+	}
+
+	// Watch for changes to Gateway API HTTPRoute in the version served by the cluster and requeue the owner GhostApp
+	if !httpRouteGVK.Empty() {
+		route := &unstructured.Unstructured{}
+		route.SetGroupVersionKind(httpRouteGVK)
+		if err := c.Watch(&source.Kind{Type: route}, owner); err != nil {
+			return err
+		}
+	}
+
+	// Watch for changes to OpenShift Route and requeue the owner GhostApp
+	if !routeGVK.Empty() {
+		route := &unstructured.Unstructured{}
+		route.SetGroupVersionKind(routeGVK)
+		if err := c.Watch(&source.Kind{Type: route}, owner); err != nil {
+			return err
+		}
+	}
+
+	// Watch for changes to ghost Pod and requeue the GhostApp it belongs to
+	if err := c.Watch(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: commonLabelToGhostApp,
+	}); err != nil {
+		return err
+	}
+
+	// Watch for changes to Endpoints of ghost Service and requeue the GhostApp it belongs to.
+	// Endpoints inherit labels of the Service, any other Endpoints is ignored.
+	if err := c.Watch(&source.Kind{Type: &corev1.Endpoints{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: commonLabelToGhostApp,
+	}); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -93,9 +188,18 @@ var _ reconcile.Reconciler = &ReconcileGhostApp{}
 type ReconcileGhostApp struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client client.Client
-	scheme *runtime.Scheme
-	logger logr.Logger
+	client   client.Client
+	scheme   *runtime.Scheme
+	logger   logr.Logger
+	recorder record.EventRecorder
+	// ingressGVK is the Ingress API version served by the cluster.
+	ingressGVK schema.GroupVersionKind
+	// certificateGVK is the cert-manager Certificate API version served by the cluster.
+	certificateGVK schema.GroupVersionKind
+	// httpRouteGVK is the Gateway API HTTPRoute version served by the cluster.
+	httpRouteGVK schema.GroupVersionKind
+	// routeGVK is the OpenShift Route version served by the cluster.
+	routeGVK schema.GroupVersionKind
 }
 
 // Reconcile reads that state of the cluster for a GhostApp object and makes changes based on the state read
@@ -121,63 +225,161 @@ func (r *ReconcileGhostApp) Reconcile(request reconcile.Request) (reconcile.Resu
 		return reconcile.Result{}, err
 	}
 
-	if err := r.CreateOrUpdateConfigMap(instance); err != nil {
-		instance.Status.Phase = ghostv1alpha1.GhostAppPhaseFailure
-		instance.Status.Reason = err.Error()
-		if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+	if instance.GetDeletionTimestamp() != nil {
+		return r.reconcileDelete(instance)
+	}
+
+	// Apply defaults to GhostApp admitted without defaulting webhook,
+	// so the stored object always shows the effective values.
+	defaulted := instance.DeepCopy()
+	defaulted.Default()
+
+	// Spec is validated before GhostApp is updated, since validating webhook rejects spec update of invalid GhostApp.
+	// Unsafe changes since the last reconcile are rejected here too, GhostApp may be updated without the webhook.
+	err := defaulted.Validate()
+	if err == nil {
+		err = defaulted.ValidateAcceptedTransition()
+	}
+	if err != nil {
+		// Retrying will not fix invalid spec, wait for the next update of GhostApp instead.
+		r.recordFailure(instance, ghostv1alpha1.GhostAppConditionConfigReady, "InvalidSpec", err)
+		setFailedStatus(instance, ghostv1alpha1.GhostAppConditionConfigReady, "InvalidSpec", err)
+		return reconcile.Result{}, r.client.Status().Update(context.TODO(), instance)
+	}
+
+	if err := r.addFinalizer(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !reflect.DeepEqual(defaulted.Spec, instance.Spec) {
+		instance.Spec = defaulted.Spec
+		if err := r.client.Update(context.TODO(), instance); err != nil {
 			return reconcile.Result{}, err
 		}
-		return reconcile.Result{}, err
+	}
+	instance.AcceptSpec()
+
+	if err := r.CreateOrUpdateConfigSecret(instance); err != nil {
+		return r.reconcileFailed(instance, ghostv1alpha1.GhostAppConditionConfigReady, "ConfigFailed", err)
 	}
 
 	// Create new PersistentVolumeClaim if persistent is enabled
 	if instance.IsPersistentEnabled() {
 		if err := r.CreateOrUpdatePersistentVolumeClaim(instance); err != nil {
-			instance.Status.Phase = ghostv1alpha1.GhostAppPhaseFailure
-			instance.Status.Reason = err.Error()
-			if err := r.client.Status().Update(context.TODO(), instance); err != nil {
-				return reconcile.Result{}, err
-			}
-			return reconcile.Result{}, err
+			return r.reconcileFailed(instance, ghostv1alpha1.GhostAppConditionStorageReady, "PersistentVolumeClaimFailed", err)
 		}
 	}
 
 	if err := r.CreateOrUpdateDeployment(instance); err != nil {
-		instance.Status.Phase = ghostv1alpha1.GhostAppPhaseFailure
-		instance.Status.Reason = err.Error()
-		if err := r.client.Status().Update(context.TODO(), instance); err != nil {
-			return reconcile.Result{}, err
-		}
-		return reconcile.Result{}, err
+		return r.reconcileFailed(instance, ghostv1alpha1.GhostAppConditionDeploymentAvailable, "DeploymentFailed", err)
 	}
 
 	if err := r.CreateOrUpdateService(instance); err != nil {
-		instance.Status.Phase = ghostv1alpha1.GhostAppPhaseFailure
-		instance.Status.Reason = err.Error()
-		if err := r.client.Status().Update(context.TODO(), instance); err != nil {
-			return reconcile.Result{}, err
+		return r.reconcileFailed(instance, ghostv1alpha1.GhostAppConditionServiceReady, "ServiceFailed", err)
+	}
+
+	// Certificate is requested before ingress, so ingress controller finds its secret as soon as possible
+	if isCertificateManaged(instance) {
+		if err := r.CreateOrUpdateCertificate(instance); err != nil {
+			return r.reconcileFailed(instance, ghostv1alpha1.GhostAppConditionIngressReady, "CertificateFailed", err)
 		}
-		return reconcile.Result{}, err
+	} else if err := r.DeleteCertificate(instance); err != nil {
+		return r.reconcileFailed(instance, ghostv1alpha1.GhostAppConditionIngressReady, "CertificateCleanupFailed", err)
 	}
 
 	if instance.IsIngressEnabled() {
 		if err := r.CreateOrUpdateIngress(instance); err != nil {
-			instance.Status.Phase = ghostv1alpha1.GhostAppPhaseFailure
-			instance.Status.Reason = err.Error()
-			if err := r.client.Status().Update(context.TODO(), instance); err != nil {
-				return reconcile.Result{}, err
-			}
-			return reconcile.Result{}, err
+			return r.reconcileFailed(instance, ghostv1alpha1.GhostAppConditionIngressReady, "IngressFailed", err)
+		}
+	} else if err := r.DeleteIngress(instance); err != nil {
+		return r.reconcileFailed(instance, ghostv1alpha1.GhostAppConditionIngressReady, "IngressCleanupFailed", err)
+	}
+
+	if instance.IsAdminEnabled() {
+		if err := r.CreateOrUpdateAdminIngress(instance); err != nil {
+			return r.reconcileFailed(instance, ghostv1alpha1.GhostAppConditionAdminIngressReady, "AdminIngressFailed", err)
+		}
+	} else if err := r.DeleteAdminIngress(instance); err != nil {
+		return r.reconcileFailed(instance, ghostv1alpha1.GhostAppConditionAdminIngressReady, "AdminIngressCleanupFailed", err)
+	}
+
+	if instance.IsGatewayEnabled() {
+		if err := r.CreateOrUpdateHTTPRoute(instance); err != nil {
+			return r.reconcileFailed(instance, ghostv1alpha1.GhostAppConditionHTTPRouteAccepted, "HTTPRouteFailed", err)
+		}
+	} else if err := r.DeleteHTTPRoute(instance); err != nil {
+		return r.reconcileFailed(instance, ghostv1alpha1.GhostAppConditionHTTPRouteAccepted, "HTTPRouteCleanupFailed", err)
+	}
+
+	if instance.IsRouteEnabled() {
+		if err := r.CreateOrUpdateRoutes(instance); err != nil {
+			return r.reconcileFailed(instance, ghostv1alpha1.GhostAppConditionRouteAdmitted, "RouteFailed", err)
+		}
+	} else if err := r.DeleteRoutes(instance); err != nil {
+		return r.reconcileFailed(instance, ghostv1alpha1.GhostAppConditionRouteAdmitted, "RouteCleanupFailed", err)
+	}
+
+	// PersistentVolumeClaim is removed only after deployment is updated to stop mounting it
+	if !instance.IsPersistentEnabled() {
+		if err := r.DeletePersistentVolumeClaim(instance); err != nil {
+			return r.reconcileFailed(instance, ghostv1alpha1.GhostAppConditionStorageReady, "PersistentVolumeClaimCleanupFailed", err)
 		}
 	}
 
-	// Set status phase to Running
-	instance.Status.Replicas = *instance.Spec.Replicas
-	instance.Status.Phase = ghostv1alpha1.GhostAppPhaseRunning
+	dep := &appsv1.Deployment{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, dep); errors.IsNotFound(err) {
+		// Deployment created by this reconcile may not be observed by the cache yet, its rollout is pending.
+		dep = nil
+	} else if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := r.updateConditions(instance, dep); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	rolloutDone := updateRolloutStatus(instance, dep)
+	if err := r.updatePodStatus(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Rollout that is blocked by failing pods will not finish by itself
+	if !rolloutDone && len(instance.Status.UnhealthyPods) > 0 {
+		instance.Status.Phase = ghostv1alpha1.GhostAppPhaseFailure
+		instance.Status.Reason = podStatusMessage(instance.Status.UnhealthyPods[0])
+	}
+
+	instance.Status.ObservedGeneration = instance.GetGeneration()
 	if err := r.client.Status().Update(context.TODO(), instance); err != nil {
 		return reconcile.Result{}, err
 	}
 
+	if !rolloutDone {
+		// Deployment status is watched as well, requeue only to make sure progress is reported.
+		return reconcile.Result{RequeueAfter: rolloutRequeueInterval}, nil
+	}
+
 	// All resource already up to date - don't requeue
 	return reconcile.Result{}, nil
 }
+
+// reconcileFailed records err in status of cr as the reason of conditionType being false and returns err,
+// so the request is requeued.
+func (r *ReconcileGhostApp) reconcileFailed(cr *ghostv1alpha1.GhostApp, conditionType ghostv1alpha1.GhostAppConditionType, reason string, err error) (reconcile.Result, error) {
+	r.recordFailure(cr, conditionType, reason, err)
+	setFailedStatus(cr, conditionType, reason, err)
+	if err := r.client.Status().Update(context.TODO(), cr); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{}, err
+}
+
+// setFailedStatus sets status phase of cr to Failure with conditionType and Ready condition false because of err.
+func setFailedStatus(cr *ghostv1alpha1.GhostApp, conditionType ghostv1alpha1.GhostAppConditionType, reason string, err error) {
+	cr.Status.Phase = ghostv1alpha1.GhostAppPhaseFailure
+	cr.Status.Reason = err.Error()
+	cr.Status.ObservedGeneration = cr.GetGeneration()
+	cr.SetCondition(conditionType, corev1.ConditionFalse, reason, err.Error())
+	cr.SetCondition(ghostv1alpha1.GhostAppConditionReady, corev1.ConditionFalse, reason, err.Error())
+}
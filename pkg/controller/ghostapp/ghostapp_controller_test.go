@@ -15,19 +15,188 @@
 package ghostapp
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 )
 
+// applyFakeClient emulates server-side apply, which is not supported by the fake client. Fields of applied object
+// are recorded in managed fields entry of the apply field manager. Applying an object removes fields that were
+// recorded by the previous apply but are no longer applied, fields set by anyone else, eg: by an update, are kept.
+// Typed objects are merged with their patch strategy, unstructured objects have no patch strategy, so they are
+// merged like a JSON merge patch.
+type applyFakeClient struct {
+	client.Client
+}
+
+func newFakeClient(initObjs ...runtime.Object) client.Client {
+	return &applyFakeClient{fake.NewFakeClient(initObjs...)}
+}
+
+func (c *applyFakeClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		if err := c.Client.Patch(ctx, obj, patch, opts...); err != nil {
+			return err
+		}
+		// API server removes managed fields patched to a single empty entry.
+		if objMeta, err := meta.Accessor(obj); err == nil && reflect.DeepEqual(objMeta.GetManagedFields(), []metav1.ManagedFieldsEntry{{}}) {
+			objMeta.SetManagedFields(nil)
+			return c.Update(ctx, obj)
+		}
+		return nil
+	}
+
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	applied, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	appliedEntry := metav1.ManagedFieldsEntry{
+		Manager:    string(fieldOwner),
+		Operation:  metav1.ManagedFieldsOperationApply,
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: applied},
+	}
+
+	newObject := func() runtime.Object {
+		o := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(runtime.Object)
+		o.GetObjectKind().SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+		return o
+	}
+
+	existing := newObject()
+	key := types.NamespacedName{Name: objMeta.GetName(), Namespace: objMeta.GetNamespace()}
+	if err := c.Get(ctx, key, existing); err != nil {
+		if errors.IsNotFound(err) {
+			objMeta.SetManagedFields([]metav1.ManagedFieldsEntry{appliedEntry})
+			return c.Create(ctx, obj)
+		}
+		return err
+	}
+
+	existingMeta, err := meta.Accessor(existing)
+	if err != nil {
+		return err
+	}
+	// fields recorded by the previous apply, none when the object has never been applied.
+	lastApplied := []byte("{}")
+	var managedFields []metav1.ManagedFieldsEntry
+	for _, entry := range existingMeta.GetManagedFields() {
+		if entry.Manager == appliedEntry.Manager && entry.Operation == appliedEntry.Operation {
+			lastApplied = entry.FieldsV1.Raw
+			continue
+		}
+		managedFields = append(managedFields, entry)
+	}
+
+	current, err := json.Marshal(existing)
+	if err != nil {
+		return err
+	}
+
+	result := newObject()
+	if _, ok := obj.(*unstructured.Unstructured); ok {
+		// Numbers are decoded as int64 like the API server does, so an unchanged object stays unchanged.
+		var original, modified, live map[string]interface{}
+		if err := utiljson.Unmarshal(lastApplied, &original); err != nil {
+			return err
+		}
+		if err := utiljson.Unmarshal(applied, &modified); err != nil {
+			return err
+		}
+		if err := utiljson.Unmarshal(current, &live); err != nil {
+			return err
+		}
+		result.(*unstructured.Unstructured).Object = mergeJSON(removeJSON(live, original, modified), modified)
+	} else {
+		patchMeta, err := strategicpatch.NewPatchMetaFromStruct(obj)
+		if err != nil {
+			return err
+		}
+		threeWay, err := strategicpatch.CreateThreeWayMergePatch(lastApplied, applied, current, patchMeta, true)
+		if err != nil {
+			return err
+		}
+		merged, err := strategicpatch.StrategicMergePatch(current, threeWay, obj)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(merged, result); err != nil {
+			return err
+		}
+	}
+
+	resultMeta, err := meta.Accessor(result)
+	if err != nil {
+		return err
+	}
+	resultMeta.SetManagedFields(append(managedFields, appliedEntry))
+
+	if !equality.Semantic.DeepEqual(existing, result) {
+		if err := c.Update(ctx, result); err != nil {
+			return err
+		}
+		existing = result
+	}
+
+	reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(existing).Elem())
+	return nil
+}
+
+// removeJSON returns a deep copy of live without fields that are in original but not in modified.
+func removeJSON(live, original, modified map[string]interface{}) map[string]interface{} {
+	result := runtime.DeepCopyJSON(live)
+	for k, v := range original {
+		if v == nil {
+			continue
+		}
+		modifiedValue, ok := modified[k]
+		if !ok || modifiedValue == nil {
+			delete(result, k)
+			continue
+		}
+		originalMap, ok := v.(map[string]interface{})
+		modifiedMap, modifiedIsMap := modifiedValue.(map[string]interface{})
+		liveMap, liveIsMap := result[k].(map[string]interface{})
+		if ok && modifiedIsMap && liveIsMap {
+			result[k] = removeJSON(liveMap, originalMap, modifiedMap)
+		}
+	}
+
+	return result
+}
+
 func TestReconciler(t *testing.T) {
 	logf.SetLogger(logf.ZapLogger(true))
 
@@ -38,7 +207,12 @@ func TestReconciler(t *testing.T) {
 	tests := []struct {
 		name    string
 		resouce *ghostv1alpha1.GhostApp
+		objs    []runtime.Object
 		wantErr bool
+		// wantRequeue is true when reconcile is expected to wait for the ghost deployment rollout.
+		wantRequeue bool
+		// wantInvalid is true when reconcile is expected to reject the spec without creating ghost deployment.
+		wantInvalid bool
 	}{
 		{
 			name: "Test Create GhostApp Instance",
@@ -61,6 +235,7 @@ func TestReconciler(t *testing.T) {
 					},
 				},
 			},
+			wantRequeue: true,
 		},
 		{
 			name: "Test Create GhostApp Instance With Persistent Volume",
@@ -87,6 +262,7 @@ func TestReconciler(t *testing.T) {
 					},
 				},
 			},
+			wantRequeue: true,
 		},
 		{
 			name: "Test Create GhostApp Instance With Ingress",
@@ -112,6 +288,7 @@ func TestReconciler(t *testing.T) {
 					},
 				},
 			},
+			wantRequeue: true,
 		},
 		{
 			name: "Test Create GhostApp Instance With Ingress TLS",
@@ -142,6 +319,152 @@ func TestReconciler(t *testing.T) {
 					},
 				},
 			},
+			wantRequeue: true,
+		},
+		{
+			name: "Test Create GhostApp Instance With Database Credentials From Secret",
+			resouce: &ghostv1alpha1.GhostApp{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ghostapp-with-database-secret",
+					Namespace: "ghost",
+				},
+				Spec: ghostv1alpha1.GhostAppSpec{
+					Replicas: &replicas,
+					Image:    "ghost:3",
+					Config: ghostv1alpha1.GhostConfigSpec{
+						URL: "http://example.ghostapp.test",
+						Database: ghostv1alpha1.GhostDatabaseSpec{
+							Client: "mysql",
+							Connection: ghostv1alpha1.GhostDatabaseConnectionSpec{
+								Host:     "mysql",
+								Database: "ghost",
+								UserSecretRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "test-ghostapp-db"},
+									Key:                  "user",
+								},
+								PasswordSecretRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "test-ghostapp-db"},
+									Key:                  "password",
+								},
+							},
+						},
+					},
+				},
+			},
+			objs: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-ghostapp-db",
+						Namespace: "ghost",
+					},
+					Data: map[string][]byte{
+						"user":     []byte("ghost"),
+						"password": []byte("secret"),
+					},
+				},
+			},
+			wantRequeue: true,
+		},
+		{
+			name: "Test Create GhostApp Instance With Missing Database Secret",
+			resouce: &ghostv1alpha1.GhostApp{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ghostapp-with-missing-database-secret",
+					Namespace: "ghost",
+				},
+				Spec: ghostv1alpha1.GhostAppSpec{
+					Replicas: &replicas,
+					Image:    "ghost:3",
+					Config: ghostv1alpha1.GhostConfigSpec{
+						URL: "http://example.ghostapp.test",
+						Database: ghostv1alpha1.GhostDatabaseSpec{
+							Client: "mysql",
+							Connection: ghostv1alpha1.GhostDatabaseConnectionSpec{
+								Host:     "mysql",
+								Database: "ghost",
+								PasswordSecretRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "test-ghostapp-db"},
+									Key:                  "password",
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test Create GhostApp Instance With SMTP Mail",
+			resouce: &ghostv1alpha1.GhostApp{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ghostapp-with-mail",
+					Namespace: "ghost",
+				},
+				Spec: ghostv1alpha1.GhostAppSpec{
+					Replicas: &replicas,
+					Image:    "ghost:3",
+					Config: ghostv1alpha1.GhostConfigSpec{
+						URL: "http://example.ghostapp.test",
+						Database: ghostv1alpha1.GhostDatabaseSpec{
+							Client: "sqlite3",
+						},
+						Mail: &ghostv1alpha1.GhostMailSpec{
+							Transport: "SMTP",
+							From:      "noreply@example.ghostapp.test",
+							Options: &ghostv1alpha1.GhostMailOptionsSpec{
+								Service: "Mailgun",
+								Auth: &ghostv1alpha1.GhostMailAuthSpec{
+									UserSecretRef: &corev1.SecretKeySelector{
+										LocalObjectReference: corev1.LocalObjectReference{Name: "test-ghostapp-mail"},
+										Key:                  "user",
+									},
+									PassSecretRef: &corev1.SecretKeySelector{
+										LocalObjectReference: corev1.LocalObjectReference{Name: "test-ghostapp-mail"},
+										Key:                  "pass",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			objs: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-ghostapp-mail",
+						Namespace: "ghost",
+					},
+					Data: map[string][]byte{
+						"user": []byte("postmaster@example.ghostapp.test"),
+						"pass": []byte("secret"),
+					},
+				},
+			},
+			wantRequeue: true,
+		},
+		{
+			name: "Test Create GhostApp Instance With Invalid Mail",
+			resouce: &ghostv1alpha1.GhostApp{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ghostapp-with-invalid-mail",
+					Namespace: "ghost",
+				},
+				Spec: ghostv1alpha1.GhostAppSpec{
+					Replicas: &replicas,
+					Image:    "ghost:3",
+					Config: ghostv1alpha1.GhostConfigSpec{
+						URL: "http://example.ghostapp.test",
+						Database: ghostv1alpha1.GhostDatabaseSpec{
+							Client: "sqlite3",
+						},
+						Mail: &ghostv1alpha1.GhostMailSpec{
+							Transport: "SMTP",
+							From:      "noreply",
+						},
+					},
+				},
+			},
+			wantInvalid: true,
 		},
 	}
 
@@ -152,7 +475,8 @@ func TestReconciler(t *testing.T) {
 
 			var objs []runtime.Object
 			objs = append(objs, tt.resouce)
-			f := fake.NewFakeClient(objs...)
+			objs = append(objs, tt.objs...)
+			f := newFakeClient(objs...)
 
 			request := reconcile.Request{
 				NamespacedName: types.NamespacedName{
@@ -161,15 +485,3003 @@ func TestReconciler(t *testing.T) {
 				},
 			}
 
-			r := ReconcileGhostApp{f, s, log}
+			r := ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100)}
 			result, err := r.Reconcile(request)
 			if err != nil && !tt.wantErr {
 				t.Fatalf("reconcile: (%v)", err)
 			}
 
-			if result != (reconcile.Result{}) {
+			if err == nil && tt.wantErr {
+				t.Fatal("reconcile: expected error, got nil")
+			}
+
+			if tt.wantRequeue && result.RequeueAfter == 0 {
+				t.Error("reconcile did not requeue while deployment rollout is in progress")
+			}
+
+			if !tt.wantRequeue && result != (reconcile.Result{}) {
 				t.Error("reconcile did not return an empty result")
 			}
+
+			if tt.wantInvalid {
+				got := &ghostv1alpha1.GhostApp{}
+				if err := f.Get(context.TODO(), request.NamespacedName, got); err != nil {
+					t.Fatalf("get ghostapp: (%v)", err)
+				}
+				if got.Status.Phase != ghostv1alpha1.GhostAppPhaseFailure {
+					t.Errorf("phase = %q, want %q", got.Status.Phase, ghostv1alpha1.GhostAppPhaseFailure)
+				}
+				if condition := got.GetCondition(ghostv1alpha1.GhostAppConditionConfigReady); condition == nil || condition.Reason != "InvalidSpec" {
+					t.Errorf("ConfigReady condition = %v, want InvalidSpec", condition)
+				}
+				if len(got.GetFinalizers()) > 0 {
+					t.Errorf("finalizers = %v, want none on invalid ghostapp", got.GetFinalizers())
+				}
+				if err := f.Get(context.TODO(), request.NamespacedName, &appsv1.Deployment{}); !errors.IsNotFound(err) {
+					t.Errorf("get deployment of invalid ghostapp: error = %v, want not found", err)
+				}
+			}
+		})
+	}
+}
+
+func TestGhostConfigFromCROmitsSecretValues(t *testing.T) {
+	cr := &ghostv1alpha1.GhostApp{
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://example.ghostapp.test",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "mysql",
+					Connection: ghostv1alpha1.GhostDatabaseConnectionSpec{
+						Host:     "mysql",
+						Password: "plaintext",
+						PasswordSecretRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "test-ghostapp-db"},
+							Key:                  "password",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	config, err := ghostConfigFromCR(cr)
+	if err != nil {
+		t.Fatalf("ghostConfigFromCR: (%v)", err)
+	}
+
+	for _, s := range []string{"plaintext", "passwordSecretRef", "test-ghostapp-db"} {
+		if strings.Contains(string(config), s) {
+			t.Errorf("rendered config contains %q: %s", s, config)
+		}
+	}
+
+	if cr.Spec.Config.Database.Connection.PasswordSecretRef == nil {
+		t.Error("ghostConfigFromCR mutated the GhostApp spec")
+	}
+}
+
+func TestCreateOrUpdateConfigSecretDeletesLegacyConfigMap(t *testing.T) {
+	cr := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://example.ghostapp.test",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, cr)
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      legacyConfigMapNameFromCR(cr),
+			Namespace: cr.GetNamespace(),
+		},
+	}
+	if err := controllerutil.SetControllerReference(cr, cm, s); err != nil {
+		t.Fatal(err)
+	}
+
+	f := newFakeClient(cr, cm)
+	r := ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100)}
+	if err := r.CreateOrUpdateConfigSecret(cr); err != nil {
+		t.Fatalf("CreateOrUpdateConfigSecret: (%v)", err)
+	}
+
+	secret := &corev1.Secret{}
+	if err := f.Get(context.TODO(), types.NamespacedName{Name: configSecretNameFromCR(cr), Namespace: cr.GetNamespace()}, secret); err != nil {
+		t.Fatalf("get config secret: (%v)", err)
+	}
+	if _, ok := secret.Data[ghostConfigFileName]; !ok {
+		t.Errorf("config secret has no %s key", ghostConfigFileName)
+	}
+
+	err := f.Get(context.TODO(), types.NamespacedName{Name: cm.GetName(), Namespace: cm.GetNamespace()}, &corev1.ConfigMap{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("legacy configmap was not deleted: (%v)", err)
+	}
+}
+
+func TestReconcilerRollsDeploymentOnConfigChange(t *testing.T) {
+	replicas := int32(1)
+	cr := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Replicas: &replicas,
+			Image:    "ghost:3",
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://example.ghostapp.test",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, cr)
+	f := newFakeClient(cr)
+	r := ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100)}
+	key := types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}
+
+	configHash := func() string {
+		if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("reconcile: (%v)", err)
+		}
+
+		dep := &appsv1.Deployment{}
+		if err := f.Get(context.TODO(), key, dep); err != nil {
+			t.Fatalf("get deployment: (%v)", err)
+		}
+
+		instance := &ghostv1alpha1.GhostApp{}
+		if err := f.Get(context.TODO(), key, instance); err != nil {
+			t.Fatalf("get ghostapp: (%v)", err)
+		}
+
+		hash := dep.Spec.Template.Annotations[configHashAnnotation]
+		if hash == "" || hash != instance.Status.ConfigHash {
+			t.Fatalf("pod template config hash %q does not match status config hash %q", hash, instance.Status.ConfigHash)
+		}
+		return hash
+	}
+
+	before := configHash()
+
+	instance := &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), key, instance); err != nil {
+		t.Fatal(err)
+	}
+	instance.Spec.Config.URL = "https://example.ghostapp.test"
+	if err := f.Update(context.TODO(), instance); err != nil {
+		t.Fatal(err)
+	}
+
+	if after := configHash(); after == before {
+		t.Error("config hash did not change after config update")
+	}
+}
+
+func TestGhostConfigFromCRMergesExtra(t *testing.T) {
+	resize := false
+	cr := &ghostv1alpha1.GhostApp{
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://example.ghostapp.test",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+				ImageOptimization: &ghostv1alpha1.GhostImageOptimizationSpec{
+					Resize: &resize,
+				},
+				Storage: &ghostv1alpha1.GhostStorageSpec{
+					Active:  "s3",
+					Options: &runtime.RawExtension{Raw: []byte(`{"bucket":"ghost"}`)},
+				},
+				Extra: &runtime.RawExtension{
+					Raw: []byte(`{"database":{"pool":{"min":2}},"imageOptimization":{"resize":true},"compress":false}`),
+				},
+			},
+		},
+	}
+
+	config, err := ghostConfigFromCR(cr)
+	if err != nil {
+		t.Fatalf("ghostConfigFromCR: (%v)", err)
+	}
+
+	rendered := make(map[string]interface{})
+	if err := json.Unmarshal(config, &rendered); err != nil {
+		t.Fatal(err)
+	}
+
+	database := rendered["database"].(map[string]interface{})
+	if database["client"] != "sqlite3" || database["pool"] == nil {
+		t.Errorf("extra was not deep-merged into database config: %v", database)
+	}
+	if resize := rendered["imageOptimization"].(map[string]interface{})["resize"]; resize != true {
+		t.Errorf("extra did not take precedence over typed config, resize: %v", resize)
+	}
+	if rendered["compress"] != false {
+		t.Errorf("extra config is missing, compress: %v", rendered["compress"])
+	}
+	if _, ok := rendered["extra"]; ok {
+		t.Error("extra field is written into ghost config")
+	}
+
+	storage := rendered["storage"].(map[string]interface{})
+	if _, ok := storage["options"]; ok {
+		t.Error("storage options field is written into ghost config")
+	}
+	if s3, ok := storage["s3"].(map[string]interface{}); !ok || s3["bucket"] != "ghost" {
+		t.Errorf("storage options are not written as storage.s3: %v", storage)
+	}
+}
+
+func TestReconcilerDefaultsGhostApp(t *testing.T) {
+	cr := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://example.ghostapp.test",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, cr)
+	f := newFakeClient(cr)
+	r := ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100)}
+	key := types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}
+
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	instance := &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), key, instance); err != nil {
+		t.Fatal(err)
+	}
+
+	if instance.Spec.Replicas == nil || instance.Spec.Image != ghostv1alpha1.DefaultImage || instance.Spec.Config.Server.Host != ghostv1alpha1.DefaultServerHost {
+		t.Errorf("stored GhostApp is not defaulted: %+v", instance.Spec)
+	}
+}
+
+func TestReconcilerRejectsUnsafeTransition(t *testing.T) {
+	cr := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://example.ghostapp.test",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, cr)
+	f := newFakeClient(cr)
+	r := ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100)}
+	key := types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}
+
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	instance := &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), key, instance); err != nil {
+		t.Fatal(err)
+	}
+	if accepted := instance.Status.AcceptedSpec; accepted == nil || accepted.DatabaseClient != "sqlite3" {
+		t.Fatalf("accepted spec = %+v, want sqlite3 database client", accepted)
+	}
+
+	// update is not validated by the webhook, since webhooks are disabled by default.
+	instance.Spec.Config.Database.Client = "mysql"
+	instance.Spec.Config.Database.Connection.Host = "mysql"
+	if err := f.Update(context.TODO(), instance); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	instance = &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), key, instance); err != nil {
+		t.Fatal(err)
+	}
+	if instance.Status.Phase != ghostv1alpha1.GhostAppPhaseFailure {
+		t.Errorf("phase = %s, want %s", instance.Status.Phase, ghostv1alpha1.GhostAppPhaseFailure)
+	}
+	if condition := instance.GetCondition(ghostv1alpha1.GhostAppConditionConfigReady); condition == nil || condition.Reason != "InvalidSpec" {
+		t.Errorf("ConfigReady condition = %+v, want InvalidSpec", condition)
+	}
+	if accepted := instance.Status.AcceptedSpec; accepted == nil || accepted.DatabaseClient != "sqlite3" {
+		t.Errorf("accepted spec = %+v, want sqlite3 database client", accepted)
+	}
+}
+
+func TestReconcilerSetsConditions(t *testing.T) {
+	replicas := int32(1)
+	cr := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-ghostapp",
+			Namespace:  "ghost",
+			Generation: 2,
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Replicas: &replicas,
+			Image:    "ghost:3",
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://example.ghostapp.test",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, cr)
+	f := newFakeClient(cr)
+	r := ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100)}
+	key := types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}
+
+	reconcileAndGet := func() *ghostv1alpha1.GhostApp {
+		if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("reconcile: (%v)", err)
+		}
+		instance := &ghostv1alpha1.GhostApp{}
+		if err := f.Get(context.TODO(), key, instance); err != nil {
+			t.Fatal(err)
+		}
+		return instance
+	}
+
+	instance := reconcileAndGet()
+	if instance.Status.ObservedGeneration != 2 {
+		t.Errorf("status.observedGeneration = %d, want 2", instance.Status.ObservedGeneration)
+	}
+	if !instance.IsConditionTrue(ghostv1alpha1.GhostAppConditionConfigReady) {
+		t.Errorf("ConfigReady condition is not true: %+v", instance.Status.Conditions)
+	}
+	if !instance.IsConditionTrue(ghostv1alpha1.GhostAppConditionStorageReady) {
+		t.Errorf("StorageReady condition is not true without persistent: %+v", instance.Status.Conditions)
+	}
+	if instance.IsConditionTrue(ghostv1alpha1.GhostAppConditionReady) {
+		t.Errorf("Ready condition is true before deployment is available: %+v", instance.Status.Conditions)
+	}
+
+	dep := &appsv1.Deployment{}
+	if err := f.Get(context.TODO(), key, dep); err != nil {
+		t.Fatal(err)
+	}
+	dep.Status.Conditions = []appsv1.DeploymentCondition{
+		{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue, Reason: "MinimumReplicasAvailable"},
+	}
+	if err := f.Status().Update(context.TODO(), dep); err != nil {
+		t.Fatal(err)
+	}
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: cr.GetName(), Namespace: cr.GetNamespace()},
+		Subsets: []corev1.EndpointSubset{
+			{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}},
+		},
+	}
+	if err := f.Create(context.TODO(), endpoints); err != nil {
+		t.Fatal(err)
+	}
+
+	instance = reconcileAndGet()
+	if !instance.IsConditionTrue(ghostv1alpha1.GhostAppConditionReady) {
+		t.Errorf("Ready condition is not true: %+v", instance.Status.Conditions)
+	}
+	if c := instance.GetCondition(ghostv1alpha1.GhostAppConditionReady); c == nil || c.ObservedGeneration != 2 || c.LastTransitionTime.IsZero() {
+		t.Errorf("Ready condition = %+v, want observedGeneration 2 and lastTransitionTime set", c)
+	}
+}
+
+// staleCacheClient reads typed objects of the stale types like an informer cache that has not observed them yet.
+// Unstructured objects are read from the API server, so they are never stale.
+type staleCacheClient struct {
+	client.Client
+	stale []runtime.Object
+}
+
+func (c *staleCacheClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	for _, stale := range c.stale {
+		if reflect.TypeOf(obj) == reflect.TypeOf(stale) {
+			return errors.NewNotFound(schema.GroupResource{}, key.Name)
+		}
+	}
+
+	return c.Client.Get(ctx, key, obj)
+}
+
+func TestReconcilerReportsPendingPersistentVolumeClaim(t *testing.T) {
+	cr := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://example.ghostapp.test",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+			Persistent: ghostv1alpha1.GhostPersistentSpec{
+				Enabled: true,
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, cr)
+	f := &staleCacheClient{Client: newFakeClient(cr), stale: []runtime.Object{&corev1.PersistentVolumeClaim{}}}
+	r := ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100)}
+	key := types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}
+
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	instance := &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), key, instance); err != nil {
+		t.Fatal(err)
+	}
+	if condition := instance.GetCondition(ghostv1alpha1.GhostAppConditionStorageReady); condition == nil ||
+		condition.Status != corev1.ConditionFalse || condition.Reason != "PersistentVolumeClaimPending" {
+		t.Errorf("StorageReady condition = %+v, want PersistentVolumeClaimPending", condition)
+	}
+}
+
+func TestReconcilerReportsPendingDeployment(t *testing.T) {
+	cr := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://example.ghostapp.test",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, cr)
+	f := &staleCacheClient{Client: newFakeClient(cr), stale: []runtime.Object{&appsv1.Deployment{}}}
+	r := ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100)}
+	key := types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}
+
+	res, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+	if err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if res.RequeueAfter != rolloutRequeueInterval {
+		t.Errorf("reconcile result = %+v, want requeue after %s", res, rolloutRequeueInterval)
+	}
+
+	instance := &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), key, instance); err != nil {
+		t.Fatal(err)
+	}
+	if instance.Status.Phase != ghostv1alpha1.GhostAppPhaseCreating {
+		t.Errorf("phase = %s, want %s", instance.Status.Phase, ghostv1alpha1.GhostAppPhaseCreating)
+	}
+	if condition := instance.GetCondition(ghostv1alpha1.GhostAppConditionDeploymentAvailable); condition == nil || condition.Reason != "DeploymentPending" {
+		t.Errorf("DeploymentAvailable condition = %+v, want DeploymentPending", condition)
+	}
+}
+
+func TestUpdateRolloutStatus(t *testing.T) {
+	replicas := int32(2)
+	tests := []struct {
+		name      string
+		phase     ghostv1alpha1.GhostAppPhaseType
+		pending   bool
+		status    appsv1.DeploymentStatus
+		wantPhase ghostv1alpha1.GhostAppPhaseType
+		wantDone  bool
+	}{
+		{
+			name:      "Test Deployment Not Observed Yet",
+			pending:   true,
+			wantPhase: ghostv1alpha1.GhostAppPhaseCreating,
+		},
+		{
+			name:      "Test New Deployment",
+			status:    appsv1.DeploymentStatus{},
+			wantPhase: ghostv1alpha1.GhostAppPhaseCreating,
+		},
+		{
+			name:      "Test Rollout Of Running GhostApp",
+			phase:     ghostv1alpha1.GhostAppPhaseRunning,
+			status:    appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 1, ReadyReplicas: 2, AvailableReplicas: 2},
+			wantPhase: ghostv1alpha1.GhostAppPhaseUpdating,
+		},
+		{
+			name:      "Test Complete Rollout",
+			phase:     ghostv1alpha1.GhostAppPhaseUpdating,
+			status:    appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2, AvailableReplicas: 2},
+			wantPhase: ghostv1alpha1.GhostAppPhaseRunning,
+			wantDone:  true,
+		},
+		{
+			name:  "Test Progress Deadline Exceeded",
+			phase: ghostv1alpha1.GhostAppPhaseUpdating,
+			status: appsv1.DeploymentStatus{
+				Replicas:        3,
+				UpdatedReplicas: 1,
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+				},
+			},
+			wantPhase: ghostv1alpha1.GhostAppPhaseFailure,
+			wantDone:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &ghostv1alpha1.GhostApp{Status: ghostv1alpha1.GhostAppStatus{Phase: tt.phase}}
+			dep := &appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: &replicas},
+				Status: tt.status,
+			}
+			if tt.pending {
+				dep = nil
+			}
+
+			done := updateRolloutStatus(cr, dep)
+			if done != tt.wantDone {
+				t.Errorf("updateRolloutStatus() = %v, want %v", done, tt.wantDone)
+			}
+			if cr.Status.Phase != tt.wantPhase {
+				t.Errorf("status.phase = %q, want %q", cr.Status.Phase, tt.wantPhase)
+			}
+			if cr.Status.Replicas != tt.status.Replicas || cr.Status.ReadyReplicas != tt.status.ReadyReplicas {
+				t.Errorf("status replicas = %d/%d, want %d/%d", cr.Status.ReadyReplicas, cr.Status.Replicas, tt.status.ReadyReplicas, tt.status.Replicas)
+			}
+		})
+	}
+}
+
+func TestReconcilerReportsUnhealthyPods(t *testing.T) {
+	replicas := int32(1)
+	cr := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Replicas: &replicas,
+			Image:    "ghost:3",
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://example.ghostapp.test",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp-7d9f8-abcde",
+			Namespace: "ghost",
+			Labels:    commonLabelFromCR(cr),
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:         "ghost",
+					RestartCount: 3,
+					State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 40s restarting failed container"},
+					},
+					LastTerminationState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137, Message: "knex-migrator failed"},
+					},
+				},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, cr)
+	f := newFakeClient(cr, pod)
+	recorder := record.NewFakeRecorder(100)
+	r := ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: recorder}
+	key := types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}
+
+	// crash looping container flips between running again and waiting to be restarted, it is still the same problem.
+	crashLoop := pod.Status.ContainerStatuses[0].State
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	for _, state := range []corev1.ContainerState{running, crashLoop} {
+		if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("reconcile: (%v)", err)
+		}
+
+		if state.Running != nil {
+			pod.Status.ContainerStatuses[0].RestartCount++
+		}
+		pod.Status.ContainerStatuses[0].State = state
+		if err := f.Update(context.TODO(), pod); err != nil {
+			t.Fatalf("update pod: (%v)", err)
+		}
+	}
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	instance := &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), key, instance); err != nil {
+		t.Fatal(err)
+	}
+
+	if instance.Status.Phase != ghostv1alpha1.GhostAppPhaseFailure {
+		t.Errorf("status.phase = %q, want %q", instance.Status.Phase, ghostv1alpha1.GhostAppPhaseFailure)
+	}
+
+	want := []ghostv1alpha1.GhostAppPodStatus{{
+		Name:               pod.GetName(),
+		Container:          "ghost",
+		Reason:             "OOMKilled",
+		Message:            "back-off 40s restarting failed container",
+		RestartCount:       4,
+		TerminationMessage: "knex-migrator failed",
+	}}
+	if !reflect.DeepEqual(instance.Status.UnhealthyPods, want) {
+		t.Errorf("status.unhealthyPods = %+v, want %+v", instance.Status.UnhealthyPods, want)
+	}
+
+	var events []string
+	for len(recorder.Events) > 0 {
+		if event := <-recorder.Events; strings.HasPrefix(event, corev1.EventTypeWarning) {
+			events = append(events, event)
+		}
+	}
+	if len(events) != 1 || !strings.HasPrefix(events[0], "Warning OOMKilled") {
+		t.Errorf("recorded events = %q, want a single OOMKilled warning", events)
+	}
+}
+
+func TestUnhealthyPodStatus(t *testing.T) {
+	waitingPod := corev1.ContainerStatus{
+		Name:  "ghost",
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}},
+	}
+	tests := []struct {
+		name string
+		init corev1.ContainerStatus
+		want []ghostv1alpha1.GhostAppPodStatus
+	}{
+		{
+			name: "Test Completed Init Container",
+			init: corev1.ContainerStatus{
+				Name:  "migrate",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}},
+			},
+		},
+		{
+			name: "Test Crash Looping Init Container",
+			init: corev1.ContainerStatus{
+				Name:         "migrate",
+				RestartCount: 2,
+				State: corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 20s restarting failed container"},
+				},
+			},
+			want: []ghostv1alpha1.GhostAppPodStatus{{
+				Name:         "test-ghostapp-7d9f8-abcde",
+				Container:    "migrate",
+				Reason:       "CrashLoopBackOff",
+				Message:      "back-off 20s restarting failed container",
+				RestartCount: 2,
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ghostapp-7d9f8-abcde"},
+				Status: corev1.PodStatus{
+					InitContainerStatuses: []corev1.ContainerStatus{tt.init},
+					ContainerStatuses:     []corev1.ContainerStatus{waitingPod},
+				},
+			}
+
+			if got := unhealthyPodStatus(pod); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unhealthyPodStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcilerRecordsEvents(t *testing.T) {
+	replicas := int32(1)
+	cr := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Replicas: &replicas,
+			Image:    "ghost:3",
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://example.ghostapp.test",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "mysql",
+					Connection: ghostv1alpha1.GhostDatabaseConnectionSpec{
+						Host: "mysql",
+						PasswordSecretRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "ghostdb"},
+							Key:                  "password",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, cr)
+	f := newFakeClient(cr)
+	recorder := record.NewFakeRecorder(100)
+	r := ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: recorder}
+	key := types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}
+
+	events := func() []string {
+		var events []string
+		for len(recorder.Events) > 0 {
+			events = append(events, <-recorder.Events)
+		}
+		return events
+	}
+
+	// Failure retried with backoff is recorded once
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err == nil {
+			t.Fatal("reconcile: expected error, got nil")
+		}
+	}
+	if got := events(); len(got) != 2 || !strings.HasPrefix(got[0], "Normal Created Created Secret") || !strings.HasPrefix(got[1], "Warning DeploymentFailed") {
+		t.Errorf("recorded events = %q, want created secret and a single deployment failure", got)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ghostdb", Namespace: "ghost"},
+		Data:       map[string][]byte{"password": []byte("s3cr3t")},
+	}
+	if err := f.Create(context.TODO(), secret); err != nil {
+		t.Fatal(err)
+	}
+
+	// Steady state reconcile does not record any event
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("reconcile: (%v)", err)
+		}
+	}
+	want := []string{
+		"Normal Created Created Deployment test-ghostapp",
+		"Normal Created Created Service test-ghostapp",
+	}
+	if got := events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("recorded events = %q, want %q", got, want)
+	}
+}
+
+func TestReconcilerAppliesDeletionPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       ghostv1alpha1.GhostAppDeletionPolicy
+		wantOwned    bool
+		wantSnapshot bool
+		wantRetained bool
+	}{
+		{
+			name:      "Test Delete Policy",
+			policy:    ghostv1alpha1.GhostAppDeletionPolicyDelete,
+			wantOwned: true,
+		},
+		{
+			name:         "Test Retain Policy",
+			policy:       ghostv1alpha1.GhostAppDeletionPolicyRetain,
+			wantRetained: true,
+		},
+		{
+			name:         "Test Snapshot Policy",
+			policy:       ghostv1alpha1.GhostAppDeletionPolicySnapshot,
+			wantOwned:    true,
+			wantSnapshot: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replicas := int32(1)
+			cr := &ghostv1alpha1.GhostApp{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ghostapp",
+					Namespace: "ghost",
+					UID:       types.UID("d5b3c0a4-6a43-4b1e-a0c1-5b1f4e0f5f6e"),
+				},
+				Spec: ghostv1alpha1.GhostAppSpec{
+					Replicas:       &replicas,
+					Image:          "ghost:3",
+					DeletionPolicy: tt.policy,
+					Config: ghostv1alpha1.GhostConfigSpec{
+						URL: "http://example.ghostapp.test",
+						Database: ghostv1alpha1.GhostDatabaseSpec{
+							Client: "sqlite3",
+						},
+					},
+					Persistent: ghostv1alpha1.GhostPersistentSpec{
+						Enabled: true,
+						Size:    resource.MustParse("1Gi"),
+					},
+				},
+			}
+
+			s := scheme.Scheme
+			s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, cr)
+			f := newFakeClient(cr)
+			r := ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100)}
+			key := types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}
+
+			if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+
+			instance := &ghostv1alpha1.GhostApp{}
+			if err := f.Get(context.TODO(), key, instance); err != nil {
+				t.Fatal(err)
+			}
+			if !containsString(instance.GetFinalizers(), ghostAppFinalizer) {
+				t.Fatalf("finalizers = %v, want %s", instance.GetFinalizers(), ghostAppFinalizer)
+			}
+
+			now := metav1.Now()
+			instance.SetDeletionTimestamp(&now)
+			if err := f.Update(context.TODO(), instance); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+			if err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+
+			pvc := &corev1.PersistentVolumeClaim{}
+			pvcKey := types.NamespacedName{Name: persistentVolumeClaimNameFromCR(cr), Namespace: cr.GetNamespace()}
+			if err := f.Get(context.TODO(), pvcKey, pvc); err != nil {
+				t.Fatal(err)
+			}
+			if owned := metav1.IsControlledBy(pvc, cr); owned != tt.wantOwned {
+				t.Errorf("persistentVolumeClaim controlled by GhostApp = %v, want %v", owned, tt.wantOwned)
+			}
+			if retained := pvc.GetLabels()[retainedFromLabel] == cr.GetName(); retained != tt.wantRetained {
+				t.Errorf("persistentVolumeClaim retained = %v, want %v", retained, tt.wantRetained)
+			}
+
+			if tt.wantSnapshot {
+				if result.RequeueAfter == 0 {
+					t.Error("reconcile did not requeue while waiting for snapshot")
+				}
+
+				snapshot := &unstructured.Unstructured{}
+				snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+				snapshotKey := types.NamespacedName{Name: fmt.Sprintf("%s-%d", pvc.GetName(), now.Unix()), Namespace: cr.GetNamespace()}
+				if err := f.Get(context.TODO(), snapshotKey, snapshot); err != nil {
+					t.Fatalf("get volumesnapshot: (%v)", err)
+				}
+				if source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName"); source != pvc.GetName() {
+					t.Errorf("volumesnapshot source = %q, want %q", source, pvc.GetName())
+				}
+
+				if err := unstructured.SetNestedField(snapshot.Object, true, "status", "readyToUse"); err != nil {
+					t.Fatal(err)
+				}
+				if err := f.Update(context.TODO(), snapshot); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+					t.Fatalf("reconcile: (%v)", err)
+				}
+			}
+
+			instance = &ghostv1alpha1.GhostApp{}
+			if err := f.Get(context.TODO(), key, instance); err != nil {
+				t.Fatal(err)
+			}
+			if containsString(instance.GetFinalizers(), ghostAppFinalizer) {
+				t.Errorf("finalizer was not removed after deletion policy is applied")
+			}
+		})
+	}
+}
+
+func TestReconcilerAppliesDeletionPolicyToRetainedClaim(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       ghostv1alpha1.GhostAppDeletionPolicy
+		wantSnapshot bool
+		wantDeleted  bool
+	}{
+		{
+			name:        "Test Delete Policy",
+			policy:      ghostv1alpha1.GhostAppDeletionPolicyDelete,
+			wantDeleted: true,
+		},
+		{
+			name:   "Test Retain Policy",
+			policy: ghostv1alpha1.GhostAppDeletionPolicyRetain,
+		},
+		{
+			name:         "Test Snapshot Policy",
+			policy:       ghostv1alpha1.GhostAppDeletionPolicySnapshot,
+			wantSnapshot: true,
+			wantDeleted:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &ghostv1alpha1.GhostApp{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ghostapp",
+					Namespace: "ghost",
+					UID:       types.UID("d5b3c0a4-6a43-4b1e-a0c1-5b1f4e0f5f6e"),
+				},
+				Spec: ghostv1alpha1.GhostAppSpec{
+					DeletionPolicy: tt.policy,
+					Config: ghostv1alpha1.GhostConfigSpec{
+						URL: "http://example.ghostapp.test",
+						Database: ghostv1alpha1.GhostDatabaseSpec{
+							Client: "sqlite3",
+						},
+					},
+					Persistent: ghostv1alpha1.GhostPersistentSpec{
+						Enabled: true,
+						Size:    resource.MustParse("1Gi"),
+					},
+				},
+			}
+
+			s := scheme.Scheme
+			s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, cr)
+			f := newFakeClient(cr)
+			r := ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100)}
+			key := types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}
+			reconcileAndGet := func() *ghostv1alpha1.GhostApp {
+				if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+					t.Fatalf("reconcile: (%v)", err)
+				}
+				instance := &ghostv1alpha1.GhostApp{}
+				if err := f.Get(context.TODO(), key, instance); err != nil && !errors.IsNotFound(err) {
+					t.Fatal(err)
+				}
+				return instance
+			}
+
+			instance := reconcileAndGet()
+			instance.Spec.Persistent.Enabled = false
+			if err := f.Update(context.TODO(), instance); err != nil {
+				t.Fatal(err)
+			}
+			instance = reconcileAndGet()
+
+			pvc := &corev1.PersistentVolumeClaim{}
+			pvcKey := types.NamespacedName{Name: persistentVolumeClaimNameFromCR(cr), Namespace: cr.GetNamespace()}
+			if err := f.Get(context.TODO(), pvcKey, pvc); err != nil {
+				t.Fatalf("get retained persistentVolumeClaim: (%v)", err)
+			}
+
+			now := metav1.Now()
+			instance.SetDeletionTimestamp(&now)
+			if err := f.Update(context.TODO(), instance); err != nil {
+				t.Fatal(err)
+			}
+			instance = reconcileAndGet()
+
+			if tt.wantSnapshot {
+				snapshot := &unstructured.Unstructured{}
+				snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+				snapshotKey := types.NamespacedName{Name: fmt.Sprintf("%s-%d", pvc.GetName(), now.Unix()), Namespace: cr.GetNamespace()}
+				if err := f.Get(context.TODO(), snapshotKey, snapshot); err != nil {
+					t.Fatalf("get volumesnapshot: (%v)", err)
+				}
+				if err := f.Get(context.TODO(), pvcKey, pvc); err != nil {
+					t.Errorf("get persistentVolumeClaim before snapshot is ready: (%v)", err)
+				}
+
+				if err := unstructured.SetNestedField(snapshot.Object, true, "status", "readyToUse"); err != nil {
+					t.Fatal(err)
+				}
+				if err := f.Update(context.TODO(), snapshot); err != nil {
+					t.Fatal(err)
+				}
+				instance = reconcileAndGet()
+			}
+
+			err := f.Get(context.TODO(), pvcKey, pvc)
+			if deleted := errors.IsNotFound(err); deleted != tt.wantDeleted {
+				t.Errorf("get retained persistentVolumeClaim: (%v), want deleted %v", err, tt.wantDeleted)
+			}
+			if containsString(instance.GetFinalizers(), ghostAppFinalizer) {
+				t.Errorf("finalizer was not removed after deletion policy is applied")
+			}
+		})
+	}
+}
+
+func TestReconcilerCleansUpDisabledFeatures(t *testing.T) {
+	tests := []struct {
+		name               string
+		policy             ghostv1alpha1.GhostAppDeletionPolicy
+		deleteWhenDisabled bool
+		wantRetained       bool
+	}{
+		{
+			name:         "Test Delete Policy",
+			policy:       ghostv1alpha1.GhostAppDeletionPolicyDelete,
+			wantRetained: true,
+		},
+		{
+			name:         "Test Retain Policy",
+			policy:       ghostv1alpha1.GhostAppDeletionPolicyRetain,
+			wantRetained: true,
+		},
+		{
+			name:               "Test Delete When Disabled",
+			policy:             ghostv1alpha1.GhostAppDeletionPolicyDelete,
+			deleteWhenDisabled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replicas := int32(1)
+			cr := &ghostv1alpha1.GhostApp{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ghostapp",
+					Namespace: "ghost",
+				},
+				Spec: ghostv1alpha1.GhostAppSpec{
+					Replicas:       &replicas,
+					Image:          "ghost:3",
+					DeletionPolicy: tt.policy,
+					Config: ghostv1alpha1.GhostConfigSpec{
+						URL: "http://example.ghostapp.test",
+						Database: ghostv1alpha1.GhostDatabaseSpec{
+							Client: "sqlite3",
+						},
+					},
+					Persistent: ghostv1alpha1.GhostPersistentSpec{
+						Enabled:            true,
+						Size:               resource.MustParse("1Gi"),
+						DeleteWhenDisabled: tt.deleteWhenDisabled,
+					},
+					Ingress: ghostv1alpha1.GhostIngressSpec{
+						Enabled: true,
+						Hosts:   []string{"example.ghostapp.test"},
+					},
+				},
+			}
+
+			s := scheme.Scheme
+			s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, cr)
+			f := newFakeClient(cr)
+			recorder := record.NewFakeRecorder(100)
+			r := ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: recorder}
+			key := types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}
+			pvcKey := types.NamespacedName{Name: persistentVolumeClaimNameFromCR(cr), Namespace: cr.GetNamespace()}
+
+			if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+
+			instance := &ghostv1alpha1.GhostApp{}
+			if err := f.Get(context.TODO(), key, instance); err != nil {
+				t.Fatal(err)
+			}
+			instance.Spec.Persistent.Enabled = false
+			instance.Spec.Ingress.Enabled = false
+			if err := f.Update(context.TODO(), instance); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+
+			if err := f.Get(context.TODO(), key, &networkingv1beta1.Ingress{}); !errors.IsNotFound(err) {
+				t.Errorf("ingress of disabled ingress was not deleted: (%v)", err)
+			}
+
+			pvc := &corev1.PersistentVolumeClaim{}
+			err := f.Get(context.TODO(), pvcKey, pvc)
+			if tt.wantRetained {
+				if err != nil || metav1.IsControlledBy(pvc, cr) || pvc.GetLabels()[retainedFromLabel] != cr.GetName() {
+					t.Errorf("persistentVolumeClaim of disabled persistent was not retained: %+v (%v)", pvc.ObjectMeta, err)
+				}
+			} else if !errors.IsNotFound(err) {
+				t.Errorf("persistentVolumeClaim of disabled persistent was not deleted: (%v)", err)
+			}
+
+			var warned bool
+			for len(recorder.Events) > 0 {
+				if strings.HasPrefix(<-recorder.Events, "Warning ContentDeleted") {
+					warned = true
+				}
+			}
+			if warned == tt.wantRetained {
+				t.Errorf("ContentDeleted warning recorded = %v, want %v", warned, !tt.wantRetained)
+			}
+
+			instance = &ghostv1alpha1.GhostApp{}
+			if err := f.Get(context.TODO(), key, instance); err != nil {
+				t.Fatal(err)
+			}
+			storage := instance.GetCondition(ghostv1alpha1.GhostAppConditionStorageReady)
+			if storage == nil || storage.Reason != "EmptyDir" || strings.Contains(storage.Message, "retained") != tt.wantRetained {
+				t.Errorf("StorageReady condition = %+v", storage)
+			}
+			if ingress := instance.GetCondition(ghostv1alpha1.GhostAppConditionIngressReady); ingress == nil || ingress.Reason != "IngressDisabled" {
+				t.Errorf("IngressReady condition = %+v", ingress)
+			}
+		})
+	}
+}
+
+func TestReconcilerCorrectsDrift(t *testing.T) {
+	replicas := int32(1)
+	cr := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Replicas: &replicas,
+			Image:    "ghost:3",
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://example.ghostapp.test",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+			Ingress: ghostv1alpha1.GhostIngressSpec{
+				Enabled: true,
+				Hosts:   []string{"example.ghostapp.test"},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, cr)
+	f := newFakeClient(cr)
+	recorder := record.NewFakeRecorder(100)
+	r := ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: recorder}
+	key := types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}
+
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	for len(recorder.Events) > 0 {
+		<-recorder.Events
+	}
+
+	svc := &corev1.Service{}
+	if err := f.Get(context.TODO(), key, svc); err != nil {
+		t.Fatal(err)
+	}
+	svc.Spec.Type = corev1.ServiceTypeLoadBalancer
+	if err := f.Update(context.TODO(), svc); err != nil {
+		t.Fatal(err)
+	}
+
+	ing := &networkingv1beta1.Ingress{}
+	if err := f.Get(context.TODO(), key, ing); err != nil {
+		t.Fatal(err)
+	}
+	// Annotation owned by another controller must be preserved
+	ing.Annotations["ingress.gcp.kubernetes.io/pre-shared-cert"] = "example"
+	ing.Spec.Rules[0].Host = "drifted.ghostapp.test"
+	if err := f.Update(context.TODO(), ing); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	svc = &corev1.Service{}
+	if err := f.Get(context.TODO(), key, svc); err != nil {
+		t.Fatal(err)
+	}
+	if svc.Spec.Type != corev1.ServiceTypeNodePort {
+		t.Errorf("service type = %q, want %q", svc.Spec.Type, corev1.ServiceTypeNodePort)
+	}
+
+	ing = &networkingv1beta1.Ingress{}
+	if err := f.Get(context.TODO(), key, ing); err != nil {
+		t.Fatal(err)
+	}
+	if ing.Spec.Rules[0].Host != "example.ghostapp.test" {
+		t.Errorf("ingress host = %q, want example.ghostapp.test", ing.Spec.Rules[0].Host)
+	}
+	if ing.GetAnnotations()["ingress.gcp.kubernetes.io/pre-shared-cert"] != "example" {
+		t.Errorf("annotation set by another controller was not preserved: %v", ing.GetAnnotations())
+	}
+
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	want := []string{
+		"Normal DriftCorrected Reverted changes to Service test-ghostapp",
+		"Normal DriftCorrected Reverted changes to Ingress test-ghostapp",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("recorded events = %q, want %q", events, want)
+	}
+}
+
+func TestReconcilerDoesNotReportSecretRotationAsDrift(t *testing.T) {
+	replicas := int32(1)
+	cr := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Replicas: &replicas,
+			Image:    "ghost:3",
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://example.ghostapp.test",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "mysql",
+					Connection: ghostv1alpha1.GhostDatabaseConnectionSpec{
+						Host: "mysql",
+						PasswordSecretRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "test-ghostapp-db"},
+							Key:                  "password",
+						},
+					},
+				},
+			},
+		},
+	}
+	dbSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ghostapp-db", Namespace: "ghost"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, cr)
+	f := newFakeClient(cr, dbSecret)
+	recorder := record.NewFakeRecorder(100)
+	r := ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: recorder}
+	key := types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}
+
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	for len(recorder.Events) > 0 {
+		<-recorder.Events
+	}
+
+	dbSecret.Data["password"] = []byte("rotated")
+	if err := f.Update(context.TODO(), dbSecret); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	want := []string{"Normal Updated Updated Deployment test-ghostapp"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("recorded events = %q, want %q", events, want)
+	}
+}
+
+func TestReconcilerTakesOverLegacyChildren(t *testing.T) {
+	replicas := int32(1)
+	cr := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+			UID:       "test-ghostapp-uid",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Replicas: &replicas,
+			Image:    "ghost:3",
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://example.ghostapp.test",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+			Ingress: ghostv1alpha1.GhostIngressSpec{
+				Enabled: true,
+			},
+		},
+	}
+
+	isController := true
+	legacyMeta := metav1.ObjectMeta{
+		Name:      cr.GetName(),
+		Namespace: cr.GetNamespace(),
+		Labels:    commonLabelFromCR(cr),
+		OwnerReferences: []metav1.OwnerReference{{
+			APIVersion: ghostv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "GhostApp",
+			Name:       cr.GetName(),
+			UID:        cr.GetUID(),
+			Controller: &isController,
+		}},
+	}
+
+	// Deployment and Service as created by CreateOrUpdate before server-side apply.
+	dep := &appsv1.Deployment{
+		ObjectMeta: legacyMeta,
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: commonLabelSelectorFromCR(cr),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: commonLabelFromCR(cr)},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "ghost",
+						Image: "ghost:3",
+						Lifecycle: &corev1.Lifecycle{
+							PostStart: &corev1.Handler{
+								Exec: &corev1.ExecAction{
+									Command: []string{"/bin/sh", "-c", "ln -sf /etc/ghost/config/config.json /var/lib/ghost/config.production.json"},
+								},
+							},
+						},
+						VolumeMounts: []corev1.VolumeMount{
+							{Name: "ghost-config", ReadOnly: true, MountPath: "/etc/ghost/config"},
+							{Name: "ghost-content", MountPath: "/var/lib/ghost/content"},
+						},
+					}},
+					Volumes: []corev1.Volume{
+						{
+							Name: "ghost-config",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: legacyConfigMapNameFromCR(cr)},
+								},
+							},
+						},
+						{
+							Name:         "ghost-content",
+							VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+						},
+					},
+				},
+			},
+		},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: legacyMeta,
+		Spec: corev1.ServiceSpec{
+			Selector:  commonLabelFromCR(cr),
+			Type:      corev1.ServiceTypeNodePort,
+			ClusterIP: "10.0.0.10",
+			Ports: []corev1.ServicePort{{
+				Name:       "http",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       2368,
+				TargetPort: intstr.FromInt(2368),
+				NodePort:   30080,
+			}},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, cr)
+	f := newFakeClient(cr, dep, svc)
+	r := ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100)}
+	key := types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}
+
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("reconcile: (%v)", err)
+		}
+	}
+
+	dep = &appsv1.Deployment{}
+	if err := f.Get(context.TODO(), key, dep); err != nil {
+		t.Fatal(err)
+	}
+	if !isApplied(dep) {
+		t.Errorf("deployment managed fields = %v, want apply entry of %s", dep.GetManagedFields(), fieldOwner)
+	}
+	if lifecycle := dep.Spec.Template.Spec.Containers[0].Lifecycle; lifecycle != nil && lifecycle.PostStart != nil {
+		t.Errorf("ghost container postStart = %v, want legacy hook removed", lifecycle.PostStart)
+	}
+	for _, volume := range dep.Spec.Template.Spec.Volumes {
+		if volume.Name == "ghost-config" && (volume.ConfigMap != nil || volume.Secret == nil) {
+			t.Errorf("ghost-config volume source = %+v, want only secret", volume.VolumeSource)
+		}
+	}
+
+	svc = &corev1.Service{}
+	if err := f.Get(context.TODO(), key, svc); err != nil {
+		t.Fatal(err)
+	}
+	if !isApplied(svc) {
+		t.Errorf("service managed fields = %v, want apply entry of %s", svc.GetManagedFields(), fieldOwner)
+	}
+	if svc.Spec.ClusterIP != "10.0.0.10" || svc.Spec.Ports[0].NodePort != 30080 {
+		t.Errorf("service clusterIP = %q nodePort = %d, want allocated 10.0.0.10 and 30080 kept", svc.Spec.ClusterIP, svc.Spec.Ports[0].NodePort)
+	}
+	if svc.Spec.Ports[0].TargetPort != intstr.FromString("http") {
+		t.Errorf("service targetPort = %v, want http", svc.Spec.Ports[0].TargetPort)
+	}
+}
+
+func TestReconcilerRemovesFieldsNoLongerDesired(t *testing.T) {
+	replicas := int32(1)
+	cr := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Replicas: &replicas,
+			Image:    "ghost:3",
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://example.ghostapp.test",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+			Ingress: ghostv1alpha1.GhostIngressSpec{
+				Enabled: true,
+				Annotations: map[string]string{
+					"nginx.ingress.kubernetes.io/proxy-body-size":        "50m",
+					"nginx.ingress.kubernetes.io/whitelist-source-range": "10.0.0.0/8",
+				},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, cr)
+	f := newFakeClient(cr)
+	r := ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100)}
+	key := types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}
+
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	ing := &unstructured.Unstructured{}
+	ing.SetGroupVersionKind(ingressV1beta1GVK)
+	if err := f.Get(context.TODO(), key, ing); err != nil {
+		t.Fatal(err)
+	}
+	// Annotation set by another controller is not owned by this operator.
+	annotations := ing.GetAnnotations()
+	annotations["ingress.gcp.kubernetes.io/pre-shared-cert"] = "example"
+	ing.SetAnnotations(annotations)
+	if err := f.Update(context.TODO(), ing); err != nil {
+		t.Fatal(err)
+	}
+
+	instance := &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), key, instance); err != nil {
+		t.Fatal(err)
+	}
+	delete(instance.Spec.Ingress.Annotations, "nginx.ingress.kubernetes.io/whitelist-source-range")
+	if err := f.Update(context.TODO(), instance); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	ing = &unstructured.Unstructured{}
+	ing.SetGroupVersionKind(ingressV1beta1GVK)
+	if err := f.Get(context.TODO(), key, ing); err != nil {
+		t.Fatal(err)
+	}
+	annotations = ing.GetAnnotations()
+	delete(annotations, appliedHashAnnotation)
+	want := map[string]string{
+		"nginx.ingress.kubernetes.io/proxy-body-size": "50m",
+		"ingress.gcp.kubernetes.io/pre-shared-cert":   "example",
+	}
+	if !reflect.DeepEqual(annotations, want) {
+		t.Errorf("ingress annotations = %v, want %v", ing.GetAnnotations(), want)
+	}
+}
+
+func TestMergePodTemplate(t *testing.T) {
+	cr := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "mysql",
+					Connection: ghostv1alpha1.GhostDatabaseConnectionSpec{
+						PasswordSecretRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "ghostdb"},
+							Key:                  "password",
+						},
+					},
+				},
+			},
+		},
+	}
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: commonLabelFromCR(cr),
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "ghost", Env: secretEnvFromCR(cr)},
+			},
+		},
+	}
+
+	custom := ghostv1alpha1.GhostPodTemplateSpec{
+		Labels: map[string]string{
+			"team":                       "blog",
+			"app.kubernetes.io/instance": "overridden",
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+		},
+		NodeSelector: map[string]string{"pool": "web"},
+		Env: []corev1.EnvVar{
+			{Name: "database__connection__password", Value: "override"},
+			{Name: "NODE_OPTIONS", Value: "--max-old-space-size=256"},
+		},
+		InitContainers: []corev1.Container{{Name: "migrate"}},
+		Sidecars: []corev1.Container{
+			{Name: "exporter"},
+			{Name: "proxy", SecurityContext: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_BIND_SERVICE"}}}},
+		},
+	}
+	mergePodTemplate(&template, custom)
+
+	if template.Labels["team"] != "blog" || template.Labels["app.kubernetes.io/instance"] != cr.GetName() {
+		t.Errorf("pod labels = %v, want custom labels without overriding selector labels", template.Labels)
+	}
+	if template.Spec.NodeSelector["pool"] != "web" {
+		t.Errorf("nodeSelector = %v, want pool=web", template.Spec.NodeSelector)
+	}
+	if len(template.Spec.Containers) != 3 || template.Spec.Containers[1].Name != "exporter" {
+		t.Fatalf("containers = %+v, want ghost, exporter and proxy", template.Spec.Containers)
+	}
+	restricted := restrictedSecurityContext()
+	if !reflect.DeepEqual(template.Spec.InitContainers[0].SecurityContext, restricted) {
+		t.Errorf("init container security context = %+v, want %+v", template.Spec.InitContainers[0].SecurityContext, restricted)
+	}
+	if !reflect.DeepEqual(template.Spec.Containers[1].SecurityContext, restricted) {
+		t.Errorf("sidecar security context = %+v, want %+v", template.Spec.Containers[1].SecurityContext, restricted)
+	}
+	if !reflect.DeepEqual(template.Spec.Containers[2].SecurityContext, custom.Sidecars[1].SecurityContext) {
+		t.Errorf("sidecar security context = %+v, want its own %+v", template.Spec.Containers[2].SecurityContext, custom.Sidecars[1].SecurityContext)
+	}
+	if custom.InitContainers[0].SecurityContext != nil || custom.Sidecars[0].SecurityContext != nil {
+		t.Errorf("custom containers = %+v %+v, want GhostApp spec untouched", custom.InitContainers, custom.Sidecars)
+	}
+
+	ghost := template.Spec.Containers[0]
+	if ghost.Resources.Requests.Memory().String() != "256Mi" {
+		t.Errorf("ghost resources = %v, want 256Mi memory request", ghost.Resources)
+	}
+	want := []corev1.EnvVar{
+		{Name: "database__connection__password", Value: "override"},
+		{Name: "NODE_OPTIONS", Value: "--max-old-space-size=256"},
+	}
+	if !reflect.DeepEqual(ghost.Env, want) {
+		t.Errorf("ghost env = %+v, want %+v", ghost.Env, want)
+	}
+}
+
+func TestProbesFromCR(t *testing.T) {
+	cr := &ghostv1alpha1.GhostApp{
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "https://blog.example.com/news/",
+			},
+		},
+	}
+
+	readiness := readinessProbeForCR(cr)
+	if readiness == nil || readiness.HTTPGet == nil {
+		t.Fatalf("readiness probe = %+v, want http probe", readiness)
+	}
+	if readiness.HTTPGet.Path != "/news/" || readiness.HTTPGet.Port.String() != "http" {
+		t.Errorf("readiness probe path = %s port = %s, want /news/ on http", readiness.HTTPGet.Path, readiness.HTTPGet.Port.String())
+	}
+	wantHeaders := []corev1.HTTPHeader{
+		{Name: "Host", Value: "blog.example.com"},
+		{Name: "X-Forwarded-Proto", Value: "https"},
+	}
+	if !reflect.DeepEqual(readiness.HTTPGet.HTTPHeaders, wantHeaders) {
+		t.Errorf("readiness probe headers = %v, want %v", readiness.HTTPGet.HTTPHeaders, wantHeaders)
+	}
+
+	if liveness := livenessProbeForCR(cr); liveness.HTTPGet.Path != "/news/robots.txt" {
+		t.Errorf("liveness probe path = %s, want /news/robots.txt", liveness.HTTPGet.Path)
+	}
+
+	cr.Spec.Probes = ghostv1alpha1.GhostProbesSpec{
+		Startup: &ghostv1alpha1.GhostProbeSpec{Disabled: true},
+		Liveness: &ghostv1alpha1.GhostProbeSpec{
+			Probe: corev1.Probe{
+				Handler: corev1.Handler{
+					TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString("http")},
+				},
+				FailureThreshold: 10,
+			},
+		},
+	}
+
+	if startup := startupProbeForCR(cr); startup != nil {
+		t.Errorf("startup probe = %+v, want disabled", startup)
+	}
+	liveness := livenessProbeForCR(cr)
+	if liveness.HTTPGet != nil || liveness.TCPSocket == nil {
+		t.Errorf("liveness probe handler = %+v, want tcp socket override", liveness.Handler)
+	}
+	if liveness.FailureThreshold != 10 || liveness.PeriodSeconds != 20 {
+		t.Errorf("liveness probe failureThreshold = %d periodSeconds = %d, want 10 and default 20", liveness.FailureThreshold, liveness.PeriodSeconds)
+	}
+}
+
+func TestReconcilerSecuresGhostPods(t *testing.T) {
+	ghostapp := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://ghost.example.com",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+			Security: ghostv1alpha1.GhostSecuritySpec{
+				ReadOnlyRootFilesystem: true,
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, ghostapp)
+	f := newFakeClient(ghostapp)
+	r := &ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100)}
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: ghostapp.GetName(), Namespace: ghostapp.GetNamespace()}}
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	dep := &appsv1.Deployment{}
+	if err := f.Get(context.TODO(), req.NamespacedName, dep); err != nil {
+		t.Fatalf("get deployment: (%v)", err)
+	}
+
+	podSecurity := dep.Spec.Template.Spec.SecurityContext
+	if podSecurity == nil || podSecurity.RunAsUser == nil || *podSecurity.RunAsUser != ghostv1alpha1.DefaultUserID ||
+		podSecurity.FSGroup == nil || *podSecurity.FSGroup != ghostv1alpha1.DefaultUserID ||
+		podSecurity.RunAsNonRoot == nil || !*podSecurity.RunAsNonRoot {
+		t.Errorf("pod security context = %+v, want non-root user %d", podSecurity, ghostv1alpha1.DefaultUserID)
+	}
+	// Typed PodSecurityContext has no seccompProfile field, so read the stored deployment as unstructured.
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+	if err := f.Get(context.TODO(), req.NamespacedName, u); err != nil {
+		t.Fatalf("get unstructured deployment: (%v)", err)
+	}
+	seccompProfile, _, _ := unstructured.NestedString(u.Object,
+		"spec", "template", "spec", "securityContext", "seccompProfile", "type")
+	if seccompProfile != seccompProfileRuntimeDefault {
+		t.Errorf("pod seccomp profile = %q, want %q", seccompProfile, seccompProfileRuntimeDefault)
+	}
+
+	ghost := dep.Spec.Template.Spec.Containers[0]
+	security := ghost.SecurityContext
+	if security == nil || security.AllowPrivilegeEscalation == nil || *security.AllowPrivilegeEscalation ||
+		security.ReadOnlyRootFilesystem == nil || !*security.ReadOnlyRootFilesystem ||
+		security.Capabilities == nil || !reflect.DeepEqual(security.Capabilities.Drop, []corev1.Capability{"ALL"}) {
+		t.Errorf("ghost security context = %+v, want restricted read-only container", security)
+	}
+
+	var tmpMounted bool
+	for _, mount := range ghost.VolumeMounts {
+		if mount.MountPath == "/tmp" && mount.Name == "ghost-tmp" {
+			tmpMounted = true
+		}
+	}
+	if !tmpMounted {
+		t.Errorf("ghost volume mounts = %+v, want writable /tmp", ghost.VolumeMounts)
+	}
+}
+
+func TestReconcilerAppliesServiceSpec(t *testing.T) {
+	ghostapp := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://ghost.example.com",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, ghostapp)
+	f := newFakeClient(ghostapp)
+	r := &ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100)}
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: ghostapp.GetName(), Namespace: ghostapp.GetNamespace()}}
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	svc := &corev1.Service{}
+	if err := f.Get(context.TODO(), req.NamespacedName, svc); err != nil {
+		t.Fatalf("get service: (%v)", err)
+	}
+	if svc.Spec.Type != corev1.ServiceTypeClusterIP || svc.Spec.Ports[0].Port != ghostv1alpha1.DefaultServicePort {
+		t.Errorf("service type = %q port = %d, want ClusterIP on %d", svc.Spec.Type, svc.Spec.Ports[0].Port, ghostv1alpha1.DefaultServicePort)
+	}
+
+	instance := &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), req.NamespacedName, instance); err != nil {
+		t.Fatalf("get ghostapp: (%v)", err)
+	}
+	instance.Spec.Service = ghostv1alpha1.GhostServiceSpec{
+		Type:                     corev1.ServiceTypeLoadBalancer,
+		Port:                     80,
+		Annotations:              map[string]string{"service.beta.kubernetes.io/aws-load-balancer-type": "nlb"},
+		LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+		ExternalTrafficPolicy:    corev1.ServiceExternalTrafficPolicyTypeLocal,
+	}
+	if err := f.Update(context.TODO(), instance); err != nil {
+		t.Fatalf("update ghostapp: (%v)", err)
+	}
+
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	svc = &corev1.Service{}
+	if err := f.Get(context.TODO(), req.NamespacedName, svc); err != nil {
+		t.Fatalf("get service: (%v)", err)
+	}
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer || svc.Spec.Ports[0].Port != 80 {
+		t.Errorf("service type = %q port = %d, want LoadBalancer on 80", svc.Spec.Type, svc.Spec.Ports[0].Port)
+	}
+	if svc.Spec.ExternalTrafficPolicy != corev1.ServiceExternalTrafficPolicyTypeLocal || !reflect.DeepEqual(svc.Spec.LoadBalancerSourceRanges, []string{"10.0.0.0/8"}) {
+		t.Errorf("service spec = %+v, want local traffic policy restricted to 10.0.0.0/8", svc.Spec)
+	}
+	if svc.GetAnnotations()["service.beta.kubernetes.io/aws-load-balancer-type"] != "nlb" {
+		t.Errorf("service annotations = %v, want load balancer annotation", svc.GetAnnotations())
+	}
+}
+
+func TestReconcilerCreatesServedIngressVersion(t *testing.T) {
+	className := "nginx"
+	tests := []struct {
+		name        string
+		ingressGVK  schema.GroupVersionKind
+		wantBackend map[string]interface{}
+	}{
+		{
+			name:       "networking.k8s.io/v1",
+			ingressGVK: ingressV1GVK,
+			wantBackend: map[string]interface{}{
+				"service": map[string]interface{}{
+					"name": "test-ghostapp",
+					"port": map[string]interface{}{"number": int64(2368)},
+				},
+			},
+		},
+		{
+			name:       "networking.k8s.io/v1beta1",
+			ingressGVK: ingressV1beta1GVK,
+			wantBackend: map[string]interface{}{
+				"serviceName": "test-ghostapp",
+				"servicePort": int64(2368),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ghostapp := &ghostv1alpha1.GhostApp{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ghostapp",
+					Namespace: "ghost",
+				},
+				Spec: ghostv1alpha1.GhostAppSpec{
+					Config: ghostv1alpha1.GhostConfigSpec{
+						URL: "http://ghost.example.com",
+						Database: ghostv1alpha1.GhostDatabaseSpec{
+							Client: "sqlite3",
+						},
+					},
+					Ingress: ghostv1alpha1.GhostIngressSpec{
+						Enabled:   true,
+						Hosts:     []string{"ghost.example.com"},
+						ClassName: &className,
+					},
+				},
+			}
+
+			s := scheme.Scheme
+			s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, ghostapp)
+			f := newFakeClient(ghostapp)
+			r := &ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100), ingressGVK: tt.ingressGVK}
+
+			req := reconcile.Request{NamespacedName: types.NamespacedName{Name: ghostapp.GetName(), Namespace: ghostapp.GetNamespace()}}
+			if _, err := r.Reconcile(req); err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+
+			ing := &unstructured.Unstructured{}
+			ing.SetGroupVersionKind(tt.ingressGVK)
+			if err := f.Get(context.TODO(), req.NamespacedName, ing); err != nil {
+				t.Fatalf("get ingress: (%v)", err)
+			}
+
+			rules, _, _ := unstructured.NestedSlice(ing.Object, "spec", "rules")
+			if len(rules) != 1 {
+				t.Fatalf("ingress rules = %v, want 1 rule", rules)
+			}
+			paths, _, _ := unstructured.NestedSlice(rules[0].(map[string]interface{}), "http", "paths")
+			if len(paths) != 1 {
+				t.Fatalf("ingress paths = %v, want 1 path", paths)
+			}
+			path := paths[0].(map[string]interface{})
+			if path["path"] != "/" || !reflect.DeepEqual(path["backend"], tt.wantBackend) {
+				t.Errorf("ingress path = %v, want / to backend %v", path, tt.wantBackend)
+			}
+
+			ingressClassName, _, _ := unstructured.NestedString(ing.Object, "spec", "ingressClassName")
+			if tt.ingressGVK == ingressV1GVK {
+				if ingressClassName != className || path["pathType"] != "Prefix" {
+					t.Errorf("ingress class = %q pathType = %v, want %q and Prefix", ingressClassName, path["pathType"], className)
+				}
+			} else if ing.GetAnnotations()[ingressClassAnnotation] != className {
+				t.Errorf("ingress annotations = %v, want %s=%s", ing.GetAnnotations(), ingressClassAnnotation, className)
+			}
+		})
+	}
+}
+
+func TestIngressTLSForCR(t *testing.T) {
+	tests := []struct {
+		name string
+		tls  ghostv1alpha1.GhostIngressTLSSpec
+		want []interface{}
+	}{
+		{
+			name: "disabled",
+			tls:  ghostv1alpha1.GhostIngressTLSSpec{SecretName: "ghost-tls"},
+		},
+		{
+			name: "single secret",
+			tls:  ghostv1alpha1.GhostIngressTLSSpec{Enabled: true, SecretName: "ghost-tls"},
+			want: []interface{}{
+				map[string]interface{}{"hosts": []interface{}{"example.com", "blog.example.org"}, "secretName": "ghost-tls"},
+			},
+		},
+		{
+			name: "default certificate",
+			tls:  ghostv1alpha1.GhostIngressTLSSpec{Enabled: true},
+			want: []interface{}{
+				map[string]interface{}{"hosts": []interface{}{"example.com", "blog.example.org"}},
+			},
+		},
+		{
+			name: "certificates",
+			tls: ghostv1alpha1.GhostIngressTLSSpec{
+				Enabled:    true,
+				SecretName: "ignored",
+				Certificates: []ghostv1alpha1.GhostIngressTLSCertificateSpec{
+					{Hosts: []string{"example.com"}, SecretName: "example-com-tls"},
+					{Hosts: []string{"blog.example.org"}},
+				},
+			},
+			want: []interface{}{
+				map[string]interface{}{"hosts": []interface{}{"example.com"}, "secretName": "example-com-tls"},
+				map[string]interface{}{"hosts": []interface{}{"blog.example.org"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &ghostv1alpha1.GhostApp{
+				Spec: ghostv1alpha1.GhostAppSpec{
+					Ingress: ghostv1alpha1.GhostIngressSpec{
+						Enabled: true,
+						Hosts:   []string{"example.com", "blog.example.org"},
+						TLS:     tt.tls,
+					},
+				},
+			}
+
+			if got := ingressTLSForCR(cr); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ingress tls = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// noMatchClient fails every request for objects of group like a cluster that does not serve group,
+// and counts the failed requests.
+type noMatchClient struct {
+	client.Client
+	group    string
+	requests int
+}
+
+func (c *noMatchClient) noMatch(obj runtime.Object) error {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.Group != c.group {
+		return nil
+	}
+
+	c.requests++
+	return &meta.NoKindMatchError{GroupKind: gvk.GroupKind(), SearchedVersions: []string{gvk.Version}}
+}
+
+func (c *noMatchClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	if err := c.noMatch(obj); err != nil {
+		return err
+	}
+
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c *noMatchClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := c.noMatch(obj); err != nil {
+		return err
+	}
+
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *noMatchClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	if err := c.noMatch(obj); err != nil {
+		return err
+	}
+
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *noMatchClient) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	if err := c.noMatch(list); err != nil {
+		return err
+	}
+
+	return c.Client.List(ctx, list, opts...)
+}
+
+func TestReconcilerRequestsCertificate(t *testing.T) {
+	ghostapp := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "https://ghost.example.com",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+			Ingress: ghostv1alpha1.GhostIngressSpec{
+				Enabled: true,
+				Hosts:   []string{"ghost.example.com"},
+				TLS: ghostv1alpha1.GhostIngressTLSSpec{
+					Enabled:   true,
+					IssuerRef: &ghostv1alpha1.GhostCertificateIssuerReference{Name: "letsencrypt", Kind: "ClusterIssuer"},
+				},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, ghostapp)
+	f := newFakeClient(ghostapp)
+	r := &ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100), certificateGVK: certificateV1GVK}
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: ghostapp.GetName(), Namespace: ghostapp.GetNamespace()}}
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(certificateV1GVK)
+	certKey := types.NamespacedName{Name: "test-ghostapp-tls", Namespace: ghostapp.GetNamespace()}
+	if err := f.Get(context.TODO(), certKey, cert); err != nil {
+		t.Fatalf("get certificate: (%v)", err)
+	}
+	dnsNames, _, _ := unstructured.NestedStringSlice(cert.Object, "spec", "dnsNames")
+	issuerRef, _, _ := unstructured.NestedStringMap(cert.Object, "spec", "issuerRef")
+	wantIssuerRef := map[string]string{"name": "letsencrypt", "kind": "ClusterIssuer", "group": "cert-manager.io"}
+	if !reflect.DeepEqual(dnsNames, []string{"ghost.example.com"}) || !reflect.DeepEqual(issuerRef, wantIssuerRef) {
+		t.Errorf("certificate dnsNames = %v issuerRef = %v, want ghost.example.com issued by %v", dnsNames, issuerRef, wantIssuerRef)
+	}
+
+	instance := &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), req.NamespacedName, instance); err != nil {
+		t.Fatalf("get ghostapp: (%v)", err)
+	}
+	if condition := instance.GetCondition(ghostv1alpha1.GhostAppConditionIngressReady); condition == nil || condition.Reason != "CertificateNotReady" {
+		t.Errorf("IngressReady condition = %+v, want CertificateNotReady", condition)
+	}
+
+	if err := unstructured.SetNestedField(cert.Object, map[string]interface{}{
+		"notAfter": "2020-06-01T00:00:00Z",
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "True", "reason": "Ready", "message": "Certificate is up to date and has not expired"},
+		},
+	}, "status"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Update(context.TODO(), cert); err != nil {
+		t.Fatalf("update certificate: (%v)", err)
+	}
+
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	instance = &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), req.NamespacedName, instance); err != nil {
+		t.Fatalf("get ghostapp: (%v)", err)
+	}
+	status := instance.Status.Certificate
+	if status == nil || !status.Ready || status.NotAfter == nil || status.NotAfter.Year() != 2020 {
+		t.Errorf("certificate status = %+v, want ready certificate expiring in 2020", status)
+	}
+	if condition := instance.GetCondition(ghostv1alpha1.GhostAppConditionIngressReady); condition == nil || condition.Reason != "AddressPending" {
+		t.Errorf("IngressReady condition = %+v, want AddressPending", condition)
+	}
+
+	ing := &networkingv1beta1.Ingress{}
+	if err := f.Get(context.TODO(), req.NamespacedName, ing); err != nil {
+		t.Fatalf("get ingress: (%v)", err)
+	}
+	if len(ing.Spec.TLS) != 1 || ing.Spec.TLS[0].SecretName != "test-ghostapp-tls" {
+		t.Errorf("ingress tls = %+v, want certificate secret test-ghostapp-tls", ing.Spec.TLS)
+	}
+}
+
+func TestReconcilerReportsMissingCertManager(t *testing.T) {
+	ghostapp := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "https://ghost.example.com",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+			Ingress: ghostv1alpha1.GhostIngressSpec{
+				Enabled: true,
+				Hosts:   []string{"ghost.example.com"},
+				TLS: ghostv1alpha1.GhostIngressTLSSpec{
+					Enabled:   true,
+					IssuerRef: &ghostv1alpha1.GhostCertificateIssuerReference{Name: "letsencrypt"},
+				},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, ghostapp)
+	// certificateGVK is not discovered, cert-manager is not installed
+	f := &noMatchClient{Client: newFakeClient(ghostapp), group: certificateV1GVK.Group}
+	recorder := record.NewFakeRecorder(100)
+	r := &ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: recorder}
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: ghostapp.GetName(), Namespace: ghostapp.GetNamespace()}}
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(req); err != nil {
+			t.Fatalf("reconcile: (%v)", err)
+		}
+	}
+
+	instance := &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), req.NamespacedName, instance); err != nil {
+		t.Fatalf("get ghostapp: (%v)", err)
+	}
+	if condition := instance.GetCondition(ghostv1alpha1.GhostAppConditionIngressReady); condition == nil || condition.Reason != "CertManagerNotInstalled" {
+		t.Errorf("IngressReady condition = %+v, want CertManagerNotInstalled", condition)
+	}
+
+	var warnings int
+	for len(recorder.Events) > 0 {
+		if event := <-recorder.Events; strings.HasPrefix(event, "Warning CertManagerNotInstalled") {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("CertManagerNotInstalled warnings = %d, want 1", warnings)
+	}
+
+	// Certificate is no longer desired, there is nothing to delete
+	instance.Spec.Ingress.TLS.IssuerRef = nil
+	if err := f.Update(context.TODO(), instance); err != nil {
+		t.Fatalf("update ghostapp: (%v)", err)
+	}
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if f.requests != 0 {
+		t.Errorf("cert-manager requests = %d, want none on a cluster without cert-manager", f.requests)
+	}
+}
+
+func TestCertificateGVKForMapper(t *testing.T) {
+	certificateV1alpha2GVK := schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1alpha2", Kind: "Certificate"}
+	tests := []struct {
+		name    string
+		served  []schema.GroupVersionKind
+		want    schema.GroupVersionKind
+		noMatch bool
+	}{
+		{
+			name:   "latest version",
+			served: []schema.GroupVersionKind{certificateV1alpha2GVK, certificateV1GVK},
+			want:   certificateV1GVK,
+		},
+		{
+			name:   "older cert-manager",
+			served: []schema.GroupVersionKind{certificateV1alpha2GVK},
+			want:   certificateV1alpha2GVK,
+		},
+		{
+			name:    "cert-manager not installed",
+			noMatch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapper := meta.NewDefaultRESTMapper(nil)
+			for _, gvk := range tt.served {
+				mapper.Add(gvk, meta.RESTScopeNamespace)
+			}
+
+			got, err := certificateGVKForMapper(mapper)
+			if tt.noMatch {
+				if !meta.IsNoMatchError(err) {
+					t.Errorf("certificateGVKForMapper() error = %v, want NoMatch error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("certificateGVKForMapper() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("certificateGVKForMapper() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateOrUpdateCertificate(t *testing.T) {
+	certificateV1alpha2GVK := schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1alpha2", Kind: "Certificate"}
+	ghostapp := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "https://ghost.example.com",
+			},
+			Ingress: ghostv1alpha1.GhostIngressSpec{
+				Enabled: true,
+				TLS: ghostv1alpha1.GhostIngressTLSSpec{
+					Enabled:   true,
+					IssuerRef: &ghostv1alpha1.GhostCertificateIssuerReference{Name: "letsencrypt"},
+				},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, ghostapp)
+	f := newFakeClient(ghostapp)
+	r := &ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100), certificateGVK: certificateV1alpha2GVK}
+
+	if err := r.CreateOrUpdateCertificate(ghostapp); err != nil {
+		t.Fatalf("CreateOrUpdateCertificate() error = %v", err)
+	}
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(certificateV1alpha2GVK)
+	certKey := types.NamespacedName{Name: "test-ghostapp-tls", Namespace: ghostapp.GetNamespace()}
+	if err := f.Get(context.TODO(), certKey, cert); err != nil {
+		t.Fatalf("get certificate: (%v)", err)
+	}
+	if dnsNames, _, _ := unstructured.NestedStringSlice(cert.Object, "spec", "dnsNames"); !reflect.DeepEqual(dnsNames, []string{"ghost.example.com"}) {
+		t.Errorf("certificate dnsNames = %v, want host of config.url", dnsNames)
+	}
+
+	ghostapp.Spec.Config.URL = "http://localhost:2368"
+	if err := r.CreateOrUpdateCertificate(ghostapp); err == nil {
+		t.Error("CreateOrUpdateCertificate() without ingress hosts error = nil, want error")
+	}
+	if err := f.Get(context.TODO(), certKey, cert); err != nil {
+		t.Fatalf("get certificate: (%v)", err)
+	}
+	if dnsNames, _, _ := unstructured.NestedStringSlice(cert.Object, "spec", "dnsNames"); !reflect.DeepEqual(dnsNames, []string{"ghost.example.com"}) {
+		t.Errorf("certificate dnsNames = %v, want certificate left untouched", dnsNames)
+	}
+}
+
+func TestReconcilerCreatesHTTPRoute(t *testing.T) {
+	ghostapp := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "https://ghost.example.com",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+			Ingress: ghostv1alpha1.GhostIngressSpec{
+				Hosts: []string{"ghost.example.com"},
+			},
+			Gateway: ghostv1alpha1.GhostGatewaySpec{
+				Enabled: true,
+				ParentRef: ghostv1alpha1.GhostGatewayParentReference{
+					Name:        "public",
+					Namespace:   "gateway",
+					SectionName: "https",
+				},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, ghostapp)
+	f := newFakeClient(ghostapp)
+	r := &ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100), httpRouteGVK: httpRouteV1beta1GVK}
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: ghostapp.GetName(), Namespace: ghostapp.GetNamespace()}}
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(httpRouteV1beta1GVK)
+	if err := f.Get(context.TODO(), req.NamespacedName, route); err != nil {
+		t.Fatalf("get httproute: (%v)", err)
+	}
+
+	wantSpec := map[string]interface{}{
+		"parentRefs": []interface{}{
+			map[string]interface{}{"name": "public", "namespace": "gateway", "sectionName": "https"},
+		},
+		"hostnames": []interface{}{"ghost.example.com"},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"matches": []interface{}{
+					map[string]interface{}{"path": map[string]interface{}{"type": "PathPrefix", "value": "/"}},
+				},
+				"backendRefs": []interface{}{
+					map[string]interface{}{"name": "test-ghostapp", "port": int64(2368)},
+				},
+			},
+		},
+	}
+	if spec := route.Object["spec"]; !reflect.DeepEqual(spec, wantSpec) {
+		t.Errorf("httproute spec = %v, want %v", spec, wantSpec)
+	}
+
+	instance := &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), req.NamespacedName, instance); err != nil {
+		t.Fatalf("get ghostapp: (%v)", err)
+	}
+	if condition := instance.GetCondition(ghostv1alpha1.GhostAppConditionHTTPRouteAccepted); condition == nil || condition.Reason != "RoutePending" {
+		t.Errorf("HTTPRouteAccepted condition = %+v, want RoutePending", condition)
+	}
+
+	// status of other parents with the same name must not be reported.
+	if err := unstructured.SetNestedSlice(route.Object, []interface{}{
+		map[string]interface{}{
+			"parentRef":      map[string]interface{}{"name": "public", "sectionName": "https"},
+			"controllerName": "example.com/gateway-controller",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Accepted", "status": "False", "reason": "NoMatchingParent", "message": "Gateway not found"},
+			},
+		},
+		map[string]interface{}{
+			"parentRef":      map[string]interface{}{"name": "public", "namespace": "gateway", "sectionName": "http"},
+			"controllerName": "example.com/gateway-controller",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Accepted", "status": "False", "reason": "NotAllowedByListeners", "message": "Listener only allows redirects"},
+			},
+		},
+		map[string]interface{}{
+			"parentRef":      map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "Gateway", "name": "public", "namespace": "gateway", "sectionName": "https"},
+			"controllerName": "example.com/gateway-controller",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Accepted", "status": "True", "reason": "Accepted", "message": "Route is accepted"},
+			},
+		},
+	}, "status", "parents"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Update(context.TODO(), route); err != nil {
+		t.Fatalf("update httproute: (%v)", err)
+	}
+
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	instance = &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), req.NamespacedName, instance); err != nil {
+		t.Fatalf("get ghostapp: (%v)", err)
+	}
+	if !instance.IsConditionTrue(ghostv1alpha1.GhostAppConditionHTTPRouteAccepted) {
+		t.Errorf("HTTPRouteAccepted condition = %+v, want true", instance.GetCondition(ghostv1alpha1.GhostAppConditionHTTPRouteAccepted))
+	}
+}
+
+func TestHTTPRouteGVKForMapper(t *testing.T) {
+	tests := []struct {
+		name    string
+		served  []schema.GroupVersionKind
+		want    schema.GroupVersionKind
+		noMatch bool
+	}{
+		{
+			name:   "latest version",
+			served: []schema.GroupVersionKind{httpRouteV1beta1GVK, httpRouteV1GVK},
+			want:   httpRouteV1GVK,
+		},
+		{
+			name:   "older gateway api",
+			served: []schema.GroupVersionKind{httpRouteV1beta1GVK},
+			want:   httpRouteV1beta1GVK,
+		},
+		{
+			name:    "gateway api not installed",
+			noMatch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapper := meta.NewDefaultRESTMapper(nil)
+			for _, gvk := range tt.served {
+				mapper.Add(gvk, meta.RESTScopeNamespace)
+			}
+
+			got, err := httpRouteGVKForMapper(mapper)
+			if tt.noMatch {
+				if !meta.IsNoMatchError(err) {
+					t.Errorf("httpRouteGVKForMapper() error = %v, want NoMatch error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("httpRouteGVKForMapper() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("httpRouteGVKForMapper() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsHTTPRouteParentOfCR(t *testing.T) {
+	cr := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Gateway: ghostv1alpha1.GhostGatewaySpec{
+				Enabled:   true,
+				ParentRef: ghostv1alpha1.GhostGatewayParentReference{Name: "public"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		parentRef map[string]interface{}
+		want      bool
+	}{
+		{
+			name:      "defaulted fields",
+			parentRef: map[string]interface{}{"name": "public"},
+			want:      true,
+		},
+		{
+			name:      "explicit defaults",
+			parentRef: map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "Gateway", "namespace": "ghost", "name": "public"},
+			want:      true,
+		},
+		{
+			name:      "other namespace",
+			parentRef: map[string]interface{}{"namespace": "gateway", "name": "public"},
+		},
+		{
+			name:      "listener",
+			parentRef: map[string]interface{}{"name": "public", "sectionName": "https"},
+		},
+		{
+			name:      "other kind",
+			parentRef: map[string]interface{}{"group": "", "kind": "Service", "name": "public"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isHTTPRouteParentOfCR(cr, tt.parentRef); got != tt.want {
+				t.Errorf("isHTTPRouteParentOfCR(%v) = %v, want %v", tt.parentRef, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcilerReportsMissingGatewayAPI(t *testing.T) {
+	ghostapp := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://ghost.example.com",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+			Gateway: ghostv1alpha1.GhostGatewaySpec{
+				Enabled:   true,
+				ParentRef: ghostv1alpha1.GhostGatewayParentReference{Name: "public"},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, ghostapp)
+	// httpRouteGVK is not discovered, Gateway API is not installed
+	f := &noMatchClient{Client: newFakeClient(ghostapp), group: gatewayGroup}
+	r := &ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100)}
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: ghostapp.GetName(), Namespace: ghostapp.GetNamespace()}}
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	instance := &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), req.NamespacedName, instance); err != nil {
+		t.Fatalf("get ghostapp: (%v)", err)
+	}
+	if condition := instance.GetCondition(ghostv1alpha1.GhostAppConditionHTTPRouteAccepted); condition == nil || condition.Reason != "GatewayAPINotInstalled" {
+		t.Errorf("HTTPRouteAccepted condition = %+v, want GatewayAPINotInstalled", condition)
+	}
+
+	// HTTPRoute is no longer desired, there is nothing to delete
+	instance.Spec.Gateway.Enabled = false
+	if err := f.Update(context.TODO(), instance); err != nil {
+		t.Fatalf("update ghostapp: (%v)", err)
+	}
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if f.requests != 0 {
+		t.Errorf("Gateway API requests = %d, want none on a cluster without Gateway API", f.requests)
+	}
+}
+
+func TestReconcilerCreatesRoutes(t *testing.T) {
+	ghostapp := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "https://example.com",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+			Ingress: ghostv1alpha1.GhostIngressSpec{
+				Hosts: []string{"example.com", "www.example.com"},
+			},
+			Route: ghostv1alpha1.GhostRouteSpec{
+				Enabled: true,
+				TLS:     &ghostv1alpha1.GhostRouteTLSSpec{},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, ghostapp)
+	// Fake client lists objects into a list type registered in the scheme
+	s.AddKnownTypeWithName(routeV1GVK.GroupVersion().WithKind("RouteList"), &unstructured.UnstructuredList{})
+	f := newFakeClient(ghostapp)
+	r := &ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100), routeGVK: routeV1GVK}
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: ghostapp.GetName(), Namespace: ghostapp.GetNamespace()}}
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(routeV1GVK)
+	wwwKey := types.NamespacedName{Name: "test-ghostapp-www-example-com", Namespace: ghostapp.GetNamespace()}
+	if err := f.Get(context.TODO(), wwwKey, route); err != nil {
+		t.Fatalf("get route: (%v)", err)
+	}
+	wantSpec := map[string]interface{}{
+		"host":           "www.example.com",
+		"to":             map[string]interface{}{"kind": "Service", "name": "test-ghostapp", "weight": int64(100)},
+		"port":           map[string]interface{}{"targetPort": "http"},
+		"wildcardPolicy": "None",
+		"tls":            map[string]interface{}{"termination": "edge", "insecureEdgeTerminationPolicy": "Redirect"},
+	}
+	if spec := route.Object["spec"]; !reflect.DeepEqual(spec, wantSpec) {
+		t.Errorf("route spec = %v, want %v", spec, wantSpec)
+	}
+
+	if err := unstructured.SetNestedSlice(route.Object, []interface{}{
+		map[string]interface{}{
+			"host":       "www.example.com",
+			"routerName": "default",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Admitted", "status": "True"},
+			},
+		},
+	}, "status", "ingress"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Update(context.TODO(), route); err != nil {
+		t.Fatalf("update route: (%v)", err)
+	}
+
+	instance := &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), req.NamespacedName, instance); err != nil {
+		t.Fatalf("get ghostapp: (%v)", err)
+	}
+	instance.Spec.Ingress.Hosts = []string{"www.example.com"}
+	if err := f.Update(context.TODO(), instance); err != nil {
+		t.Fatalf("update ghostapp: (%v)", err)
+	}
+
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	apexRoute := &unstructured.Unstructured{}
+	apexRoute.SetGroupVersionKind(routeV1GVK)
+	apexKey := types.NamespacedName{Name: "test-ghostapp-example-com", Namespace: ghostapp.GetNamespace()}
+	if err := f.Get(context.TODO(), apexKey, apexRoute); !errors.IsNotFound(err) {
+		t.Errorf("get route of removed host: (%v), want not found", err)
+	}
+
+	instance = &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), req.NamespacedName, instance); err != nil {
+		t.Fatalf("get ghostapp: (%v)", err)
+	}
+	wantRoutes := []ghostv1alpha1.GhostAppRouteStatus{{Name: "test-ghostapp-www-example-com", Host: "www.example.com", Admitted: true}}
+	if !reflect.DeepEqual(instance.Status.Routes, wantRoutes) {
+		t.Errorf("route status = %+v, want %+v", instance.Status.Routes, wantRoutes)
+	}
+	if !instance.IsConditionTrue(ghostv1alpha1.GhostAppConditionRouteAdmitted) {
+		t.Errorf("RouteAdmitted condition = %+v, want true", instance.GetCondition(ghostv1alpha1.GhostAppConditionRouteAdmitted))
+	}
+}
+
+func TestReconcilerReportsMissingRouteAPI(t *testing.T) {
+	ghostapp := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "http://ghost.example.com",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+			Route: ghostv1alpha1.GhostRouteSpec{
+				Enabled: true,
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, ghostapp)
+	// routeGVK is not discovered, the cluster is not OpenShift
+	f := &noMatchClient{Client: newFakeClient(ghostapp), group: routeV1GVK.Group}
+	r := &ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100)}
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: ghostapp.GetName(), Namespace: ghostapp.GetNamespace()}}
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	instance := &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), req.NamespacedName, instance); err != nil {
+		t.Fatalf("get ghostapp: (%v)", err)
+	}
+	if condition := instance.GetCondition(ghostv1alpha1.GhostAppConditionRouteAdmitted); condition == nil || condition.Reason != "RouteAPINotInstalled" {
+		t.Errorf("RouteAdmitted condition = %+v, want RouteAPINotInstalled", condition)
+	}
+
+	// Route is no longer desired, there is nothing to delete
+	instance.Spec.Route.Enabled = false
+	if err := f.Update(context.TODO(), instance); err != nil {
+		t.Fatalf("update ghostapp: (%v)", err)
+	}
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	if f.requests != 0 {
+		t.Errorf("Route API requests = %d, want none on a cluster without Route API", f.requests)
+	}
+}
+
+func TestRoutesForCR(t *testing.T) {
+	cr := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "https://example.com",
+			},
+			Ingress: ghostv1alpha1.GhostIngressSpec{
+				Hosts: []string{"example.com", "*.example.com"},
+			},
+			Route: ghostv1alpha1.GhostRouteSpec{
+				Enabled: true,
+			},
+		},
+	}
+
+	routes := routesForCR(cr, routeV1GVK)
+	if len(routes) != 2 {
+		t.Fatalf("routes = %v, want one route for each host", routes)
+	}
+
+	want := map[string][2]string{
+		"test-ghostapp-example-com":          {"example.com", "None"},
+		"test-ghostapp-wildcard-example-com": {"wildcard.example.com", "Subdomain"},
+	}
+	for _, route := range routes {
+		host, _, _ := unstructured.NestedString(route.Object, "spec", "host")
+		wildcardPolicy, _, _ := unstructured.NestedString(route.Object, "spec", "wildcardPolicy")
+		if got := [2]string{host, wildcardPolicy}; got != want[route.GetName()] {
+			t.Errorf("route %s host and wildcardPolicy = %v, want %v", route.GetName(), got, want[route.GetName()])
+		}
+	}
+}
+
+func TestReconcilerDerivesIngressFromURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		ingress    ghostv1alpha1.GhostIngressSpec
+		wantHosts  []string
+		wantPath   string
+		wantReason string
+	}{
+		{
+			name:       "subdirectory",
+			url:        "https://blog.example.com/news/",
+			ingress:    ghostv1alpha1.GhostIngressSpec{Enabled: true, TLS: ghostv1alpha1.GhostIngressTLSSpec{Enabled: true}},
+			wantHosts:  []string{"blog.example.com"},
+			wantPath:   "/news",
+			wantReason: "URLMatched",
+		},
+		{
+			name:       "catch-all",
+			url:        "http://localhost:2368",
+			ingress:    ghostv1alpha1.GhostIngressSpec{Enabled: true},
+			wantHosts:  []string{""},
+			wantPath:   "/",
+			wantReason: "URLMatched",
+		},
+		{
+			name:       "https without tls",
+			url:        "https://blog.example.com",
+			ingress:    ghostv1alpha1.GhostIngressSpec{Enabled: true},
+			wantHosts:  []string{"blog.example.com"},
+			wantPath:   "/",
+			wantReason: "TLSNotEnabled",
+		},
+		{
+			name:       "host mismatch",
+			url:        "http://blog.example.com",
+			ingress:    ghostv1alpha1.GhostIngressSpec{Enabled: true, Hosts: []string{"www.example.com"}},
+			wantHosts:  []string{"www.example.com"},
+			wantPath:   "/",
+			wantReason: "HostMismatch",
+		},
+		{
+			name:       "path mismatch",
+			url:        "http://blog.example.com/news",
+			ingress:    ghostv1alpha1.GhostIngressSpec{Enabled: true, Path: "/blog"},
+			wantHosts:  []string{"blog.example.com"},
+			wantPath:   "/blog",
+			wantReason: "PathMismatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ghostapp := &ghostv1alpha1.GhostApp{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ghostapp",
+					Namespace: "ghost",
+				},
+				Spec: ghostv1alpha1.GhostAppSpec{
+					Config: ghostv1alpha1.GhostConfigSpec{
+						URL: tt.url,
+						Database: ghostv1alpha1.GhostDatabaseSpec{
+							Client: "sqlite3",
+						},
+					},
+					Ingress: tt.ingress,
+				},
+			}
+
+			s := scheme.Scheme
+			s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, ghostapp)
+			f := newFakeClient(ghostapp)
+			r := &ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100)}
+
+			req := reconcile.Request{NamespacedName: types.NamespacedName{Name: ghostapp.GetName(), Namespace: ghostapp.GetNamespace()}}
+			if _, err := r.Reconcile(req); err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+
+			ing := &networkingv1beta1.Ingress{}
+			if err := f.Get(context.TODO(), req.NamespacedName, ing); err != nil {
+				t.Fatalf("get ingress: (%v)", err)
+			}
+			var hosts []string
+			for _, rule := range ing.Spec.Rules {
+				hosts = append(hosts, rule.Host)
+				if path := rule.HTTP.Paths[0].Path; path != tt.wantPath {
+					t.Errorf("ingress path = %s, want %s", path, tt.wantPath)
+				}
+			}
+			if !reflect.DeepEqual(hosts, tt.wantHosts) {
+				t.Errorf("ingress hosts = %q, want %q", hosts, tt.wantHosts)
+			}
+
+			instance := &ghostv1alpha1.GhostApp{}
+			if err := f.Get(context.TODO(), req.NamespacedName, instance); err != nil {
+				t.Fatalf("get ghostapp: (%v)", err)
+			}
+			if condition := instance.GetCondition(ghostv1alpha1.GhostAppConditionURLConsistent); condition == nil || condition.Reason != tt.wantReason {
+				t.Errorf("URLConsistent condition = %+v, want %s", condition, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestReconcilerCreatesAdminIngress(t *testing.T) {
+	className := "nginx"
+	ghostapp := &ghostv1alpha1.GhostApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ghostapp",
+			Namespace: "ghost",
+		},
+		Spec: ghostv1alpha1.GhostAppSpec{
+			Config: ghostv1alpha1.GhostConfigSpec{
+				URL: "https://ghost.example.com/blog/",
+				Database: ghostv1alpha1.GhostDatabaseSpec{
+					Client: "sqlite3",
+				},
+			},
+			Ingress: ghostv1alpha1.GhostIngressSpec{
+				Enabled:   true,
+				ClassName: &className,
+				TLS:       ghostv1alpha1.GhostIngressTLSSpec{Enabled: true},
+			},
+			Admin: ghostv1alpha1.GhostAppAdminSpec{
+				Enabled: true,
+				Host:    "ghost-admin.internal.example.com",
+				TLS:     ghostv1alpha1.GhostAdminTLSSpec{Enabled: true, SecretName: "ghost-admin-tls"},
+				Annotations: map[string]string{
+					"nginx.ingress.kubernetes.io/whitelist-source-range": "10.0.0.0/8",
+				},
+			},
+		},
+	}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(ghostv1alpha1.SchemeGroupVersion, ghostapp)
+	f := newFakeClient(ghostapp)
+	r := &ReconcileGhostApp{client: f, scheme: s, logger: log, recorder: record.NewFakeRecorder(100), ingressGVK: ingressV1GVK}
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: ghostapp.GetName(), Namespace: ghostapp.GetNamespace()}}
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	ing := &unstructured.Unstructured{}
+	ing.SetGroupVersionKind(ingressV1GVK)
+	if err := f.Get(context.TODO(), req.NamespacedName, ing); err != nil {
+		t.Fatalf("get public ingress: (%v)", err)
+	}
+
+	admin := &unstructured.Unstructured{}
+	admin.SetGroupVersionKind(ingressV1GVK)
+	adminKey := types.NamespacedName{Name: "test-ghostapp-admin", Namespace: ghostapp.GetNamespace()}
+	if err := f.Get(context.TODO(), adminKey, admin); err != nil {
+		t.Fatalf("get admin ingress: (%v)", err)
+	}
+
+	rules, _, _ := unstructured.NestedSlice(admin.Object, "spec", "rules")
+	if len(rules) != 1 || rules[0].(map[string]interface{})["host"] != "ghost-admin.internal.example.com" {
+		t.Fatalf("admin ingress rules = %v, want 1 rule of admin host", rules)
+	}
+	paths, _, _ := unstructured.NestedSlice(rules[0].(map[string]interface{}), "http", "paths")
+	if len(paths) != 1 || paths[0].(map[string]interface{})["path"] != "/blog/ghost" {
+		t.Errorf("admin ingress paths = %v, want only /blog/ghost", paths)
+	}
+
+	if ingressClassName, _, _ := unstructured.NestedString(admin.Object, "spec", "ingressClassName"); ingressClassName != className {
+		t.Errorf("admin ingress class = %q, want %q of ingress", ingressClassName, className)
+	}
+
+	if admin.GetAnnotations()["nginx.ingress.kubernetes.io/whitelist-source-range"] != "10.0.0.0/8" {
+		t.Errorf("admin ingress annotations = %v, want admin annotations", admin.GetAnnotations())
+	}
+
+	tls, _, _ := unstructured.NestedSlice(admin.Object, "spec", "tls")
+	wantTLS := []interface{}{map[string]interface{}{
+		"hosts":      []interface{}{"ghost-admin.internal.example.com"},
+		"secretName": "ghost-admin-tls",
+	}}
+	if !reflect.DeepEqual(tls, wantTLS) {
+		t.Errorf("admin ingress tls = %v, want %v", tls, wantTLS)
+	}
+
+	got := &ghostv1alpha1.GhostApp{}
+	if err := f.Get(context.TODO(), req.NamespacedName, got); err != nil {
+		t.Fatalf("get ghostapp: (%v)", err)
+	}
+
+	rendered, err := ghostConfigFromCR(got)
+	if err != nil {
+		t.Fatalf("ghostConfigFromCR: (%v)", err)
+	}
+	config := struct {
+		URL   string `json:"url"`
+		Admin struct {
+			URL string `json:"url"`
+		} `json:"admin"`
+	}{}
+	if err := json.Unmarshal(rendered, &config); err != nil {
+		t.Fatalf("unmarshal config: (%v)", err)
+	}
+	if config.URL != "https://ghost.example.com/blog/" || config.Admin.URL != "https://ghost-admin.internal.example.com" {
+		t.Errorf("config url = %q admin.url = %q, want public url and admin host url", config.URL, config.Admin.URL)
+	}
+
+	if condition := got.GetCondition(ghostv1alpha1.GhostAppConditionAdminIngressReady); condition == nil || condition.Reason != "AddressPending" {
+		t.Errorf("AdminIngressReady condition = %v, want AddressPending", condition)
+	}
+
+	got.Spec.Admin.Enabled = false
+	if err := f.Update(context.TODO(), got); err != nil {
+		t.Fatalf("update ghostapp: (%v)", err)
+	}
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	if err := f.Get(context.TODO(), adminKey, admin); !errors.IsNotFound(err) {
+		t.Errorf("get admin ingress of disabled admin: error = %v, want not found", err)
+	}
+	if err := f.Get(context.TODO(), req.NamespacedName, ing); err != nil {
+		t.Errorf("get public ingress: (%v)", err)
+	}
+}
+
+func TestCommonLabelToGhostApp(t *testing.T) {
+	ghostapp := &ghostv1alpha1.GhostApp{ObjectMeta: metav1.ObjectMeta{Name: "test-ghostapp", Namespace: "ghost"}}
+	tests := []struct {
+		name string
+		obj  *corev1.Endpoints
+		want []reconcile.Request
+	}{
+		{
+			name: "Endpoints Of Ghost Service",
+			obj: &corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{
+				Name: "test-ghostapp", Namespace: "ghost", Labels: commonLabelFromCR(ghostapp),
+			}},
+			want: []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "test-ghostapp", Namespace: "ghost"}}},
+		},
+		{
+			name: "Unrelated Endpoints",
+			obj: &corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{
+				Name: "kube-dns", Namespace: "kube-system", Labels: map[string]string{"k8s-app": "kube-dns"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := commonLabelToGhostApp.Map(handler.MapObject{Meta: tt.obj, Object: tt.obj})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("requests = %v, want %v", got, tt.want)
+			}
 		})
 	}
 }
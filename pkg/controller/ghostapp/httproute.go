@@ -0,0 +1,211 @@
+// Copyright 2020 Fossil Dev
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ghostapp
+
+import (
+	"context"
+	"fmt"
+
+	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const gatewayGroup = "gateway.networking.k8s.io"
+
+var (
+	httpRouteV1GVK      = schema.GroupVersionKind{Group: gatewayGroup, Version: "v1", Kind: "HTTPRoute"}
+	httpRouteV1beta1GVK = schema.GroupVersionKind{Group: gatewayGroup, Version: "v1beta1", Kind: "HTTPRoute"}
+)
+
+// httpRouteGVKForMapper returns the latest HTTPRoute API version served by the cluster.
+// A NoMatch error is returned when Gateway API is not installed.
+func httpRouteGVKForMapper(mapper meta.RESTMapper) (schema.GroupVersionKind, error) {
+	mapping, err := mapper.RESTMapping(httpRouteV1GVK.GroupKind(), httpRouteV1GVK.Version, httpRouteV1beta1GVK.Version)
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+
+	return mapping.GroupVersionKind, nil
+}
+
+// CreateOrUpdateHTTPRoute attaches ghost service to the parent gateway of cr for ingress hosts and path.
+// Missing Gateway API is not an error, it is reported by HTTPRouteAccepted condition instead.
+func (r *ReconcileGhostApp) CreateOrUpdateHTTPRoute(cr *ghostv1alpha1.GhostApp) error {
+	if r.httpRouteGVK.Empty() {
+		r.logger.Info("HTTPRoute API is not served, Gateway API is not installed")
+		return nil
+	}
+
+	route := httpRouteForCR(cr, r.httpRouteGVK)
+	op, err := r.apply(cr, route)
+	r.logger.Info("Reconciling HTTPRoute", "Operation.Result", op)
+	r.recordOperation(cr, "HTTPRoute", route.GetName(), op)
+	return err
+}
+
+func httpRouteForCR(cr *ghostv1alpha1.GhostApp, gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	matchType := "PathPrefix"
+	if cr.Spec.Ingress.PathType == ghostv1alpha1.GhostIngressPathTypeExact {
+		matchType = "Exact"
+	}
+
+	spec := map[string]interface{}{
+		"parentRefs": []interface{}{httpRouteParentRefForCR(cr)},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"matches": []interface{}{
+					map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":  matchType,
+							"value": cr.IngressPath(),
+						},
+					},
+				},
+				// we only route to backend service created by this operator.
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"name": cr.GetName(),
+						"port": int64(cr.Spec.Service.Port),
+					},
+				},
+			},
+		},
+	}
+	if hosts := cr.IngressHosts(); len(hosts) > 0 {
+		spec["hostnames"] = stringsToInterfaces(hosts)
+	}
+
+	route := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": spec,
+	}}
+	route.SetGroupVersionKind(gvk)
+	route.SetName(cr.GetName())
+	route.SetNamespace(cr.GetNamespace())
+	route.SetLabels(commonLabelFromCR(cr))
+	return route
+}
+
+func httpRouteParentRefForCR(cr *ghostv1alpha1.GhostApp) map[string]interface{} {
+	parentRef := cr.Spec.Gateway.ParentRef
+	ref := map[string]interface{}{
+		"name": parentRef.Name,
+	}
+	if parentRef.Namespace != "" {
+		ref["namespace"] = parentRef.Namespace
+	}
+	if parentRef.SectionName != "" {
+		ref["sectionName"] = parentRef.SectionName
+	}
+
+	return ref
+}
+
+// isHTTPRouteParentOfCR returns true when parentRef reported in HTTPRoute status references the parent gateway of cr.
+// Omitted fields of parentRef are compared with their defaults, namespace defaults to the HTTPRoute namespace.
+func isHTTPRouteParentOfCR(cr *ghostv1alpha1.GhostApp, parentRef map[string]interface{}) bool {
+	want := cr.Spec.Gateway.ParentRef
+	wantNamespace := want.Namespace
+	if wantNamespace == "" {
+		wantNamespace = cr.GetNamespace()
+	}
+
+	group, found, _ := unstructured.NestedString(parentRef, "group")
+	if !found {
+		group = gatewayGroup
+	}
+	kind, found, _ := unstructured.NestedString(parentRef, "kind")
+	if !found {
+		kind = "Gateway"
+	}
+	namespace, _, _ := unstructured.NestedString(parentRef, "namespace")
+	if namespace == "" {
+		namespace = cr.GetNamespace()
+	}
+	name, _, _ := unstructured.NestedString(parentRef, "name")
+	sectionName, _, _ := unstructured.NestedString(parentRef, "sectionName")
+
+	return group == gatewayGroup && kind == "Gateway" && namespace == wantNamespace &&
+		name == want.Name && sectionName == want.SectionName
+}
+
+// DeleteHTTPRoute deletes HTTPRoute of cr that is no longer desired, so it stops routing traffic to ghost.
+// Clusters without Gateway API are not queried, since every request of an unknown kind reloads API discovery.
+func (r *ReconcileGhostApp) DeleteHTTPRoute(cr *ghostv1alpha1.GhostApp) error {
+	if r.httpRouteGVK.Empty() {
+		return nil
+	}
+
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(r.httpRouteGVK)
+	return r.deleteOwnedObject(cr, "HTTPRoute", cr.GetName(), route)
+}
+
+// updateHTTPRouteCondition sets HTTPRouteAccepted condition of cr from the status reported by its parent gateway.
+func (r *ReconcileGhostApp) updateHTTPRouteCondition(cr *ghostv1alpha1.GhostApp) error {
+	if !cr.IsGatewayEnabled() {
+		cr.SetCondition(ghostv1alpha1.GhostAppConditionHTTPRouteAccepted, corev1.ConditionTrue, "GatewayDisabled",
+			"Gateway is not enabled")
+		return nil
+	}
+
+	if r.httpRouteGVK.Empty() {
+		r.setWarningCondition(cr, ghostv1alpha1.GhostAppConditionHTTPRouteAccepted, "GatewayAPINotInstalled",
+			fmt.Sprintf("Gateway API is not installed, HTTPRoute %s can not be created", cr.GetName()))
+		return nil
+	}
+
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(r.httpRouteGVK)
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}, route)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	parentName := cr.Spec.Gateway.ParentRef.Name
+	parents, _, _ := unstructured.NestedSlice(route.Object, "status", "parents")
+	for _, p := range parents {
+		parent, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		parentRef, _, _ := unstructured.NestedMap(parent, "parentRef")
+		if !isHTTPRouteParentOfCR(cr, parentRef) {
+			continue
+		}
+
+		conditions, _, _ := unstructured.NestedSlice(parent, "conditions")
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok || condition["type"] != "Accepted" {
+				continue
+			}
+			status, _ := condition["status"].(string)
+			reason, _ := condition["reason"].(string)
+			message, _ := condition["message"].(string)
+			cr.SetCondition(ghostv1alpha1.GhostAppConditionHTTPRouteAccepted, corev1.ConditionStatus(status), reason,
+				fmt.Sprintf("HTTPRoute %s on Gateway %s: %s", cr.GetName(), parentName, message))
+			return nil
+		}
+	}
+
+	cr.SetCondition(ghostv1alpha1.GhostAppConditionHTTPRouteAccepted, corev1.ConditionFalse, "RoutePending",
+		fmt.Sprintf("HTTPRoute %s is not accepted by Gateway %s yet", cr.GetName(), parentName))
+	return nil
+}
@@ -15,73 +15,232 @@
 package ghostapp
 
 import (
-	"context"
+	"path"
 
 	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+// ingressClassAnnotation selects ingress controller of networking.k8s.io/v1beta1 Ingress.
+const ingressClassAnnotation = "kubernetes.io/ingress.class"
+
+var (
+	ingressV1GVK      = schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}
+	ingressV1beta1GVK = networkingv1beta1.SchemeGroupVersion.WithKind("Ingress")
+)
+
+// ingressGVKForMapper returns the latest Ingress API version served by the cluster.
+// The Kubernetes API version used by this operator has no networking.k8s.io/v1 types,
+// so Ingress is always handled as an unstructured object.
+func ingressGVKForMapper(mapper meta.RESTMapper) (schema.GroupVersionKind, error) {
+	mapping, err := mapper.RESTMapping(ingressV1GVK.GroupKind(), ingressV1GVK.Version, ingressV1beta1GVK.Version)
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+
+	return mapping.GroupVersionKind, nil
+}
+
+// ingressKind returns GroupVersionKind of Ingress managed by r, networking.k8s.io/v1beta1 unless discovered otherwise.
+func (r *ReconcileGhostApp) ingressKind() schema.GroupVersionKind {
+	if r.ingressGVK.Empty() {
+		return ingressV1beta1GVK
+	}
+
+	return r.ingressGVK
+}
+
 func (r *ReconcileGhostApp) CreateOrUpdateIngress(cr *ghostv1alpha1.GhostApp) error {
-	ing := &networkingv1beta1.Ingress{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:        cr.GetName(),
-			Namespace:   cr.GetNamespace(),
-			Labels:      commonLabelFromCR(cr),
-			Annotations: cr.Spec.Ingress.Annotations,
-		},
+	ing := ingressForCR(cr, r.ingressKind())
+	op, err := r.apply(cr, ing)
+	r.logger.Info("Reconciling Ingress", "Operation.Result", op)
+	r.recordOperation(cr, "Ingress", ing.GetName(), op)
+	return err
+}
+
+// ingressForCR returns ingress of cr in the given API version.
+func ingressForCR(cr *ghostv1alpha1.GhostApp, gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	spec := cr.Spec.Ingress
+	return newIngress(cr, gvk, ingressOptions{
+		name:        cr.GetName(),
+		hosts:       cr.IngressHosts(),
+		path:        cr.IngressPath(),
+		pathType:    spec.PathType,
+		className:   spec.ClassName,
+		annotations: spec.Annotations,
+		tls:         ingressTLSForCR(cr),
+	})
+}
+
+// adminIngressNameFromCR returns name of ingress serving ghost admin panel of cr.
+func adminIngressNameFromCR(cr *ghostv1alpha1.GhostApp) string {
+	return cr.GetName() + "-admin"
+}
+
+func (r *ReconcileGhostApp) CreateOrUpdateAdminIngress(cr *ghostv1alpha1.GhostApp) error {
+	ing := adminIngressForCR(cr, r.ingressKind())
+	op, err := r.apply(cr, ing)
+	r.logger.Info("Reconciling admin Ingress", "Operation.Result", op)
+	r.recordOperation(cr, "Ingress", ing.GetName(), op)
+	return err
+}
+
+// adminIngressForCR returns ingress routing only ghost admin panel on admin host of cr in the given API version.
+// Ghost serves its admin panel under /ghost of config.url subdirectory.
+func adminIngressForCR(cr *ghostv1alpha1.GhostApp, gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	spec := cr.Spec.Admin
+	className := spec.ClassName
+	if className == nil {
+		className = cr.Spec.Ingress.ClassName
 	}
 
-	op, err := controllerutil.CreateOrUpdate(context.TODO(), r.client, ing, func() error {
-		if err := controllerutil.SetControllerReference(cr, ing, r.scheme); err != nil {
-			return err
+	var tls []interface{}
+	if spec.TLS.Enabled {
+		entry := map[string]interface{}{
+			"hosts": []interface{}{spec.Host},
+		}
+		if spec.TLS.SecretName != "" {
+			entry["secretName"] = spec.TLS.SecretName
 		}
+		tls = append(tls, entry)
+	}
+
+	return newIngress(cr, gvk, ingressOptions{
+		name:        adminIngressNameFromCR(cr),
+		hosts:       []string{spec.Host},
+		path:        path.Join(cr.IngressPath(), "ghost"),
+		pathType:    ghostv1alpha1.GhostIngressPathTypePrefix,
+		className:   className,
+		annotations: spec.Annotations,
+		tls:         tls,
+	})
+}
+
+// ingressOptions defines ingress routing a path of some hosts to ghost service.
+type ingressOptions struct {
+	name        string
+	hosts       []string
+	path        string
+	pathType    ghostv1alpha1.GhostIngressPathType
+	className   *string
+	annotations map[string]string
+	tls         []interface{}
+}
+
+// newIngress returns ingress of cr defined by opts in the given API version.
+func newIngress(cr *ghostv1alpha1.GhostApp, gvk schema.GroupVersionKind, opts ingressOptions) *unstructured.Unstructured {
+	v1 := gvk.Version == ingressV1GVK.Version
 
-		// we only create ingress rule with backend service created by this operator.
-		ingressRule := networkingv1beta1.IngressRuleValue{
-			HTTP: &networkingv1beta1.HTTPIngressRuleValue{
-				Paths: []networkingv1beta1.HTTPIngressPath{
-					{
-						Backend: networkingv1beta1.IngressBackend{
-							ServiceName: cr.GetName(),
-							ServicePort: intstr.FromInt(int(2368)),
-						},
-					},
+	// we only create ingress rule with backend service created by this operator.
+	ingressPath := map[string]interface{}{
+		"path": opts.path,
+	}
+	if v1 {
+		ingressPath["pathType"] = string(opts.pathType)
+		ingressPath["backend"] = map[string]interface{}{
+			"service": map[string]interface{}{
+				"name": cr.GetName(),
+				"port": map[string]interface{}{
+					"number": int64(cr.Spec.Service.Port),
 				},
 			},
 		}
+	} else {
+		ingressPath["backend"] = map[string]interface{}{
+			"serviceName": cr.GetName(),
+			"servicePort": int64(cr.Spec.Service.Port),
+		}
+	}
+	ingressRule := map[string]interface{}{
+		"paths": []interface{}{ingressPath},
+	}
 
-		hosts := cr.Spec.Ingress.Hosts
-		rules := []networkingv1beta1.IngressRule{}
-		// if no one hosts defined, create rule without any spesific host.
-		if len(hosts) == 0 {
-			rules = append(rules, networkingv1beta1.IngressRule{
-				IngressRuleValue: ingressRule,
+	rules := []interface{}{}
+	// if no one hosts defined, create rule without any spesific host.
+	if len(opts.hosts) == 0 {
+		rules = append(rules, map[string]interface{}{
+			"http": ingressRule,
+		})
+	} else {
+		for _, host := range opts.hosts {
+			rules = append(rules, map[string]interface{}{
+				"host": host,
+				"http": ingressRule,
 			})
-		} else {
-			for _, host := range hosts {
-				rules = append(rules, networkingv1beta1.IngressRule{
-					Host:             host,
-					IngressRuleValue: ingressRule,
-				})
-			}
 		}
+	}
 
-		ing.Spec.Rules = rules
-		// if tls is enabled add `IngressTLS` with defined hosts.
-		// NOTE: hosts is required when tls is enabled.
-		if cr.Spec.Ingress.TLS.Enabled {
-			ing.Spec.TLS = append(ing.Spec.TLS, networkingv1beta1.IngressTLS{
-				Hosts:      hosts,
-				SecretName: cr.Spec.Ingress.TLS.SecretName,
-			})
+	ingSpec := map[string]interface{}{
+		"rules": rules,
+	}
+
+	annotations := opts.annotations
+	if opts.className != nil {
+		if v1 {
+			ingSpec["ingressClassName"] = *opts.className
+		} else {
+			annotations = mergeStringMap(annotations, map[string]string{ingressClassAnnotation: *opts.className})
 		}
+	}
+
+	if len(opts.tls) > 0 {
+		ingSpec["tls"] = opts.tls
+	}
+
+	ing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": ingSpec,
+	}}
+	ing.SetGroupVersionKind(gvk)
+	ing.SetName(opts.name)
+	ing.SetNamespace(cr.GetNamespace())
+	ing.SetLabels(commonLabelFromCR(cr))
+	ing.SetAnnotations(annotations)
+	return ing
+}
 
+// ingressTLSForCR returns tls entries of cr ingress. Every ingress host is served by tls secretName unless
+// certificates are defined. Entry without secretName is served by ingress controller default certificate.
+// NOTE: hosts is required when tls is enabled.
+func ingressTLSForCR(cr *ghostv1alpha1.GhostApp) []interface{} {
+	spec := cr.Spec.Ingress.TLS
+	if !spec.Enabled {
 		return nil
-	})
+	}
 
-	r.logger.Info("Reconciling Ingress", "Operation.Result", op)
-	return err
+	certificates := spec.Certificates
+	if isCertificateManaged(cr) {
+		certificates = []ghostv1alpha1.GhostIngressTLSCertificateSpec{
+			{Hosts: cr.IngressHosts(), SecretName: certificateSecretNameFromCR(cr)},
+		}
+	} else if len(certificates) == 0 {
+		certificates = []ghostv1alpha1.GhostIngressTLSCertificateSpec{
+			{Hosts: cr.IngressHosts(), SecretName: spec.SecretName},
+		}
+	}
+
+	var tls []interface{}
+	for _, certificate := range certificates {
+		entry := map[string]interface{}{
+			"hosts": stringsToInterfaces(certificate.Hosts),
+		}
+		if certificate.SecretName != "" {
+			entry["secretName"] = certificate.SecretName
+		}
+		tls = append(tls, entry)
+	}
+
+	return tls
+}
+
+// stringsToInterfaces converts list into a list that can be set in unstructured object.
+func stringsToInterfaces(list []string) []interface{} {
+	result := make([]interface{}, 0, len(list))
+	for _, s := range list {
+		result = append(result, s)
+	}
+
+	return result
 }
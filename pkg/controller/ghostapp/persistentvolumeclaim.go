@@ -19,8 +19,10 @@ import (
 
 	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func (r *ReconcileGhostApp) CreateOrUpdatePersistentVolumeClaim(cr *ghostv1alpha1.GhostApp) error {
@@ -32,29 +34,49 @@ func (r *ReconcileGhostApp) CreateOrUpdatePersistentVolumeClaim(cr *ghostv1alpha
 			Namespace: cr.GetNamespace(),
 			Labels:    commonLabelFromCR(cr),
 		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{
+				corev1.ReadWriteOnce,
+			},
+			StorageClassName: cr.Spec.Persistent.StorageClass,
+			Resources: corev1.ResourceRequirements{
+				Requests: requestStorage,
+			},
+		},
 	}
 
-	op, err := controllerutil.CreateOrUpdate(context.TODO(), r.client, pvc, func() error {
-		if err := controllerutil.SetControllerReference(cr, pvc, r.scheme); err != nil {
-			return err
-		}
+	if err := r.adoptRetainedPersistentVolumeClaim(cr); err != nil {
+		return err
+	}
 
-		if pvc.ObjectMeta.CreationTimestamp.IsZero() {
-			pvc.Spec = corev1.PersistentVolumeClaimSpec{
-				AccessModes: []corev1.PersistentVolumeAccessMode{
-					corev1.ReadWriteOnce,
-				},
-				StorageClassName: cr.Spec.Persistent.StorageClass,
-			}
-		}
+	op, err := r.apply(cr, pvc)
+	r.logger.Info("Reconciling PersistentVolumeClaim", "Operation.Result", op)
+	r.recordOperation(cr, "PersistentVolumeClaim", pvc.GetName(), op)
+	return err
+}
 
-		pvc.Spec.Resources = corev1.ResourceRequirements{
-			Requests: requestStorage,
+// adoptRetainedPersistentVolumeClaim removes retainedFromLabel from persistentVolumeClaim retained by a deleted GhostApp
+// with the same name as cr, so it is used by cr again.
+func (r *ReconcileGhostApp) adoptRetainedPersistentVolumeClaim(cr *ghostv1alpha1.GhostApp) error {
+	pvc := &corev1.PersistentVolumeClaim{}
+	key := types.NamespacedName{Name: persistentVolumeClaimNameFromCR(cr), Namespace: cr.GetNamespace()}
+	if err := r.client.Get(context.TODO(), key, pvc); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
 		}
+		return err
+	}
 
+	if _, ok := pvc.GetLabels()[retainedFromLabel]; !ok {
 		return nil
-	})
+	}
 
-	r.logger.Info("Reconciling PersistentVolumeClaim", "Operation.Result", op)
-	return err
+	patch := client.MergeFrom(pvc.DeepCopy())
+	delete(pvc.Labels, retainedFromLabel)
+	if err := r.client.Patch(context.TODO(), pvc, patch); err != nil {
+		return err
+	}
+
+	r.recorder.Eventf(cr, corev1.EventTypeNormal, "Adopted", "Adopted retained PersistentVolumeClaim %s", pvc.GetName())
+	return nil
 }
@@ -0,0 +1,155 @@
+// Copyright 2020 Fossil Dev
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ghostapp
+
+import (
+	"context"
+	"fmt"
+
+	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// updatePodStatus inspects ghost pods of cr, reports failing containers in status
+// and records a warning event for every problem not reported before.
+func (r *ReconcileGhostApp) updatePodStatus(cr *ghostv1alpha1.GhostApp) error {
+	pods := &corev1.PodList{}
+	if err := r.client.List(context.TODO(), pods, client.InNamespace(cr.GetNamespace()), client.MatchingLabels(commonLabelFromCR(cr))); err != nil {
+		return err
+	}
+
+	var unhealthy []ghostv1alpha1.GhostAppPodStatus
+	for i := range pods.Items {
+		unhealthy = append(unhealthy, unhealthyPodStatus(&pods.Items[i])...)
+	}
+
+	for _, pod := range unhealthy {
+		if !containsPodStatus(cr.Status.UnhealthyPods, pod) {
+			r.recorder.Event(cr, corev1.EventTypeWarning, pod.Reason, podStatusMessage(pod))
+		}
+	}
+
+	cr.Status.UnhealthyPods = unhealthy
+	return nil
+}
+
+// unhealthyPodStatus returns problems of pod and its containers.
+func unhealthyPodStatus(pod *corev1.Pod) []ghostv1alpha1.GhostAppPodStatus {
+	if pod.GetDeletionTimestamp() != nil {
+		return nil
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+			return []ghostv1alpha1.GhostAppPodStatus{{
+				Name:    pod.GetName(),
+				Reason:  condition.Reason,
+				Message: condition.Message,
+			}}
+		}
+	}
+
+	var unhealthy []ghostv1alpha1.GhostAppPodStatus
+	// Pod containers wait for init containers, so a failing init container is the only problem reported.
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for i := range statuses {
+			if status, ok := unhealthyContainerStatus(pod, &statuses[i]); ok {
+				unhealthy = append(unhealthy, status)
+			}
+		}
+	}
+
+	return unhealthy
+}
+
+// unhealthyContainerStatus returns problem of container status cs of pod, false when the container is healthy or still starting.
+func unhealthyContainerStatus(pod *corev1.Pod, cs *corev1.ContainerStatus) (ghostv1alpha1.GhostAppPodStatus, bool) {
+	status := ghostv1alpha1.GhostAppPodStatus{
+		Name:         pod.GetName(),
+		Container:    cs.Name,
+		RestartCount: cs.RestartCount,
+	}
+	// Init container that completed successfully is not ready by design.
+	if cs.Ready || (cs.State.Terminated != nil && cs.State.Terminated.ExitCode == 0) {
+		return status, false
+	}
+
+	lastTerminated := cs.LastTerminationState.Terminated
+	if lastTerminated != nil {
+		status.TerminationMessage = lastTerminated.Message
+	}
+
+	switch {
+	case cs.State.Waiting != nil && !isStartingReason(cs.State.Waiting.Reason):
+		status.Reason = cs.State.Waiting.Reason
+		status.Message = cs.State.Waiting.Message
+		// Crash loop of a container killed by the kernel is reported as what it actually is.
+		if lastTerminated != nil && lastTerminated.Reason == "OOMKilled" {
+			status.Reason = lastTerminated.Reason
+		}
+	case cs.State.Terminated != nil:
+		status.Reason = cs.State.Terminated.Reason
+		status.Message = fmt.Sprintf("container exited with code %d", cs.State.Terminated.ExitCode)
+		status.TerminationMessage = cs.State.Terminated.Message
+	case cs.RestartCount > 0:
+		status.Reason = "Restarting"
+		if lastTerminated != nil {
+			status.Message = fmt.Sprintf("container last terminated with %s, exit code %d", lastTerminated.Reason, lastTerminated.ExitCode)
+		}
+	default:
+		// Container is still starting
+		return status, false
+	}
+
+	return status, true
+}
+
+// isStartingReason returns true when a container waits with reason that is part of normal startup.
+func isStartingReason(reason string) bool {
+	return reason == "" || reason == "ContainerCreating" || reason == "PodInitializing"
+}
+
+// containsPodStatus returns true when a problem of the same container is in list.
+// Reason and restart count are not compared, since a crash looping container flips between waiting in
+// CrashLoopBackOff and restarting, so it is reported once instead of on every restart.
+func containsPodStatus(list []ghostv1alpha1.GhostAppPodStatus, status ghostv1alpha1.GhostAppPodStatus) bool {
+	for _, s := range list {
+		if s.Name == status.Name && s.Container == status.Container {
+			return true
+		}
+	}
+
+	return false
+}
+
+func podStatusMessage(status ghostv1alpha1.GhostAppPodStatus) string {
+	message := fmt.Sprintf("Pod %s", status.Name)
+	if status.Container != "" {
+		message += fmt.Sprintf(" container %s", status.Container)
+	}
+	message += fmt.Sprintf(": %s", status.Reason)
+	if status.Message != "" {
+		message += fmt.Sprintf(": %s", status.Message)
+	}
+	if status.RestartCount > 0 {
+		message += fmt.Sprintf(" (restarted %d times)", status.RestartCount)
+	}
+	if status.TerminationMessage != "" {
+		message += fmt.Sprintf(", last termination message: %s", status.TerminationMessage)
+	}
+
+	return message
+}
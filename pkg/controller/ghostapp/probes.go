@@ -0,0 +1,110 @@
+// Copyright 2020 Fossil Dev
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ghostapp
+
+import (
+	"net/url"
+	"strings"
+
+	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// startupProbeForCR returns startup probe of ghost container. Ghost runs database migrations before it starts
+// serving, so the default probe allows up to 10 minutes for the first boot.
+func startupProbeForCR(cr *ghostv1alpha1.GhostApp) *corev1.Probe {
+	return mergeProbe(&corev1.Probe{
+		Handler:          ghostHTTPGetHandler(cr, ""),
+		PeriodSeconds:    10,
+		TimeoutSeconds:   5,
+		FailureThreshold: 60,
+	}, cr.Spec.Probes.Startup)
+}
+
+// readinessProbeForCR returns readiness probe of ghost container.
+func readinessProbeForCR(cr *ghostv1alpha1.GhostApp) *corev1.Probe {
+	return mergeProbe(&corev1.Probe{
+		Handler:          ghostHTTPGetHandler(cr, ""),
+		PeriodSeconds:    10,
+		TimeoutSeconds:   5,
+		FailureThreshold: 3,
+	}, cr.Spec.Probes.Readiness)
+}
+
+// livenessProbeForCR returns liveness probe of ghost container. It requests robots.txt,
+// which is served by ghost without rendering any theme template.
+func livenessProbeForCR(cr *ghostv1alpha1.GhostApp) *corev1.Probe {
+	return mergeProbe(&corev1.Probe{
+		Handler:          ghostHTTPGetHandler(cr, "robots.txt"),
+		PeriodSeconds:    20,
+		TimeoutSeconds:   5,
+		FailureThreshold: 3,
+	}, cr.Spec.Probes.Liveness)
+}
+
+// ghostHTTPGetHandler returns http handler requesting path relative to ghost site root.
+// Ghost only serves requests for the host of config.url and redirects plain http requests when config.url is https,
+// so the request is sent as if it came through the ingress.
+func ghostHTTPGetHandler(cr *ghostv1alpha1.GhostApp, path string) corev1.Handler {
+	action := &corev1.HTTPGetAction{
+		Path:   "/" + path,
+		Port:   intstr.FromString("http"),
+		Scheme: corev1.URISchemeHTTP,
+	}
+
+	if u, err := url.Parse(cr.Spec.Config.URL); err == nil && u.Host != "" {
+		action.Path = strings.TrimSuffix(u.Path, "/") + "/" + path
+		action.HTTPHeaders = append(action.HTTPHeaders, corev1.HTTPHeader{Name: "Host", Value: u.Host})
+		if u.Scheme == "https" {
+			action.HTTPHeaders = append(action.HTTPHeaders, corev1.HTTPHeader{Name: "X-Forwarded-Proto", Value: "https"})
+		}
+	}
+
+	return corev1.Handler{HTTPGet: action}
+}
+
+// mergeProbe returns probe with fields defined in spec overriding fields of probe,
+// or nil when spec disables the probe.
+func mergeProbe(probe *corev1.Probe, spec *ghostv1alpha1.GhostProbeSpec) *corev1.Probe {
+	if spec == nil {
+		return probe
+	}
+
+	if spec.Disabled {
+		return nil
+	}
+
+	if spec.Exec != nil || spec.HTTPGet != nil || spec.TCPSocket != nil {
+		probe.Handler = *spec.Handler.DeepCopy()
+	}
+	if spec.InitialDelaySeconds != 0 {
+		probe.InitialDelaySeconds = spec.InitialDelaySeconds
+	}
+	if spec.TimeoutSeconds != 0 {
+		probe.TimeoutSeconds = spec.TimeoutSeconds
+	}
+	if spec.PeriodSeconds != 0 {
+		probe.PeriodSeconds = spec.PeriodSeconds
+	}
+	if spec.SuccessThreshold != 0 {
+		probe.SuccessThreshold = spec.SuccessThreshold
+	}
+	if spec.FailureThreshold != 0 {
+		probe.FailureThreshold = spec.FailureThreshold
+	}
+
+	return probe
+}
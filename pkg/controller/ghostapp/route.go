@@ -0,0 +1,271 @@
+// Copyright 2020 Fossil Dev
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ghostapp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var routeV1GVK = schema.GroupVersionKind{
+	Group:   "route.openshift.io",
+	Version: "v1",
+	Kind:    "Route",
+}
+
+// routeGVKForMapper returns the OpenShift Route API version served by the cluster.
+// A NoMatch error is returned when the cluster is not OpenShift.
+func routeGVKForMapper(mapper meta.RESTMapper) (schema.GroupVersionKind, error) {
+	mapping, err := mapper.RESTMapping(routeV1GVK.GroupKind(), routeV1GVK.Version)
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+
+	return mapping.GroupVersionKind, nil
+}
+
+// CreateOrUpdateRoutes creates an OpenShift Route for every ingress host of cr, or a single Route with host
+// generated by the router when no host is defined. Routes of hosts no longer defined are deleted.
+// Missing Route API is not an error, it is reported by RouteAdmitted condition instead.
+func (r *ReconcileGhostApp) CreateOrUpdateRoutes(cr *ghostv1alpha1.GhostApp) error {
+	if r.routeGVK.Empty() {
+		r.logger.Info("Route API is not served, cluster is not OpenShift")
+		return nil
+	}
+
+	routes := routesForCR(cr, r.routeGVK)
+	for _, route := range routes {
+		op, err := r.apply(cr, route)
+		r.logger.Info("Reconciling Route", "Route.Name", route.GetName(), "Operation.Result", op)
+		r.recordOperation(cr, "Route", route.GetName(), op)
+		if err != nil {
+			return err
+		}
+	}
+
+	return r.deleteRoutes(cr, routes)
+}
+
+// DeleteRoutes deletes every Route of cr, so they stop routing traffic to ghost.
+// Clusters without Route API are not queried, since every request of an unknown kind reloads API discovery.
+func (r *ReconcileGhostApp) DeleteRoutes(cr *ghostv1alpha1.GhostApp) error {
+	if r.routeGVK.Empty() {
+		return nil
+	}
+
+	return r.deleteRoutes(cr, nil)
+}
+
+// deleteRoutes deletes Routes controlled by cr except routes to keep.
+func (r *ReconcileGhostApp) deleteRoutes(cr *ghostv1alpha1.GhostApp, keep []*unstructured.Unstructured) error {
+	routes, err := r.listRoutes(cr)
+	if err != nil {
+		return err
+	}
+
+	for i := range routes {
+		route := &routes[i]
+		if !metav1.IsControlledBy(route, cr) || containsRoute(keep, route.GetName()) {
+			continue
+		}
+		if err := r.deleteOwnedObject(cr, "Route", route.GetName(), route); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ReconcileGhostApp) listRoutes(cr *ghostv1alpha1.GhostApp) ([]unstructured.Unstructured, error) {
+	routes := &unstructured.UnstructuredList{}
+	routes.SetGroupVersionKind(r.routeGVK.GroupVersion().WithKind(r.routeGVK.Kind + "List"))
+	if err := r.client.List(context.TODO(), routes, client.InNamespace(cr.GetNamespace()), client.MatchingLabels(commonLabelFromCR(cr))); err != nil {
+		return nil, err
+	}
+
+	return routes.Items, nil
+}
+
+func routesForCR(cr *ghostv1alpha1.GhostApp, gvk schema.GroupVersionKind) []*unstructured.Unstructured {
+	hosts := cr.IngressHosts()
+	if len(hosts) == 0 {
+		// Router generates host of Route without host
+		return []*unstructured.Unstructured{routeForCR(cr, gvk, cr.GetName(), "")}
+	}
+
+	var routes []*unstructured.Unstructured
+	for _, host := range hosts {
+		routes = append(routes, routeForCR(cr, gvk, routeNameFromHost(cr, host), host))
+	}
+
+	return routes
+}
+
+// routeNameFromHost returns name of cr Route for host, eg: "blog-www-example-com" for host "www.example.com".
+func routeNameFromHost(cr *ghostv1alpha1.GhostApp, host string) string {
+	return cr.GetName() + "-" + strings.Replace(strings.Replace(host, ".", "-", -1), "*", "wildcard", -1)
+}
+
+// routeHostForHost returns Route host and wildcard policy routing host. Route host can not contain a wildcard,
+// wildcard host like *.example.com is routed by a Subdomain policy Route for a host of the subdomain instead.
+// Routers only admit such Route when wildcard routes are allowed by their route admission policy.
+func routeHostForHost(host string) (string, string) {
+	if strings.HasPrefix(host, "*.") {
+		return "wildcard" + strings.TrimPrefix(host, "*"), "Subdomain"
+	}
+
+	return host, "None"
+}
+
+func routeForCR(cr *ghostv1alpha1.GhostApp, gvk schema.GroupVersionKind, name, host string) *unstructured.Unstructured {
+	routeHost, wildcardPolicy := routeHostForHost(host)
+	spec := map[string]interface{}{
+		// we only route to backend service created by this operator.
+		"to": map[string]interface{}{
+			"kind":   "Service",
+			"name":   cr.GetName(),
+			"weight": int64(100),
+		},
+		"port": map[string]interface{}{
+			"targetPort": "http",
+		},
+		"wildcardPolicy": wildcardPolicy,
+	}
+	if routeHost != "" {
+		spec["host"] = routeHost
+	}
+	if path := cr.IngressPath(); path != "/" {
+		spec["path"] = path
+	}
+
+	if tls := cr.Spec.Route.TLS; tls != nil {
+		routeTLS := map[string]interface{}{
+			"termination":                   string(tls.Termination),
+			"insecureEdgeTerminationPolicy": tls.InsecureEdgeTerminationPolicy,
+		}
+		if tls.DestinationCACertificate != "" {
+			routeTLS["destinationCACertificate"] = tls.DestinationCACertificate
+		}
+		spec["tls"] = routeTLS
+	}
+
+	route := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": spec,
+	}}
+	route.SetGroupVersionKind(gvk)
+	route.SetName(name)
+	route.SetNamespace(cr.GetNamespace())
+	route.SetLabels(commonLabelFromCR(cr))
+	route.SetAnnotations(cr.Spec.Route.Annotations)
+	return route
+}
+
+func containsRoute(routes []*unstructured.Unstructured, name string) bool {
+	for _, route := range routes {
+		if route.GetName() == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// updateRouteCondition reports Routes of cr and their admitted hosts in its status.
+func (r *ReconcileGhostApp) updateRouteCondition(cr *ghostv1alpha1.GhostApp) error {
+	if !cr.IsRouteEnabled() {
+		cr.Status.Routes = nil
+		cr.SetCondition(ghostv1alpha1.GhostAppConditionRouteAdmitted, corev1.ConditionTrue, "RouteDisabled",
+			"Route is not enabled")
+		return nil
+	}
+
+	if r.routeGVK.Empty() {
+		cr.Status.Routes = nil
+		r.setWarningCondition(cr, ghostv1alpha1.GhostAppConditionRouteAdmitted, "RouteAPINotInstalled",
+			"Route API is not served, the cluster is not OpenShift")
+		return nil
+	}
+
+	routes, err := r.listRoutes(cr)
+	if err != nil {
+		return err
+	}
+
+	var statuses []ghostv1alpha1.GhostAppRouteStatus
+	var pending []string
+	for i := range routes {
+		route := &routes[i]
+		if !metav1.IsControlledBy(route, cr) {
+			continue
+		}
+
+		status := routeStatus(route)
+		if !status.Admitted {
+			pending = append(pending, status.Name)
+		}
+		statuses = append(statuses, status)
+	}
+	cr.Status.Routes = statuses
+
+	switch {
+	case len(statuses) == 0:
+		cr.SetCondition(ghostv1alpha1.GhostAppConditionRouteAdmitted, corev1.ConditionFalse, "RoutePending",
+			"Route is not created yet")
+	case len(pending) > 0:
+		cr.SetCondition(ghostv1alpha1.GhostAppConditionRouteAdmitted, corev1.ConditionFalse, "RoutePending",
+			fmt.Sprintf("Route %s is not admitted by any router yet", strings.Join(pending, ", ")))
+	default:
+		cr.SetCondition(ghostv1alpha1.GhostAppConditionRouteAdmitted, corev1.ConditionTrue, "RouteAdmitted",
+			"Every Route is admitted")
+	}
+
+	return nil
+}
+
+// routeStatus returns status of route from the status reported by OpenShift routers.
+func routeStatus(route *unstructured.Unstructured) ghostv1alpha1.GhostAppRouteStatus {
+	status := ghostv1alpha1.GhostAppRouteStatus{Name: route.GetName()}
+	status.Host, _, _ = unstructured.NestedString(route.Object, "spec", "host")
+
+	ingresses, _, _ := unstructured.NestedSlice(route.Object, "status", "ingress")
+	for _, i := range ingresses {
+		ingress, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		conditions, _, _ := unstructured.NestedSlice(ingress, "conditions")
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if ok && condition["type"] == "Admitted" && condition["status"] == string(corev1.ConditionTrue) {
+				status.Admitted = true
+				// Host generated by the router is only known from the router status
+				status.Host, _, _ = unstructured.NestedString(ingress, "host")
+				return status
+			}
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,120 @@
+// Copyright 2020 Fossil Dev
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ghostapp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// secretEnvFromCR returns environment variables for every ghost config value referenced from a secret.
+// Ghost reads its configuration through nconf, so "database__connection__password" overrides
+// database.connection.password from config.production.json without the value ever leaving the secret.
+func secretEnvFromCR(cr *ghostv1alpha1.GhostApp) []corev1.EnvVar {
+	var env []corev1.EnvVar
+	conn := cr.Spec.Config.Database.Connection
+	if conn.UserSecretRef != nil {
+		env = append(env, secretEnvVar("database__connection__user", conn.UserSecretRef))
+	}
+	if conn.PasswordSecretRef != nil {
+		env = append(env, secretEnvVar("database__connection__password", conn.PasswordSecretRef))
+	}
+
+	if mail := cr.Spec.Config.Mail; mail != nil && mail.Options != nil && mail.Options.Auth != nil {
+		if mail.Options.Auth.UserSecretRef != nil {
+			env = append(env, secretEnvVar("mail__options__auth__user", mail.Options.Auth.UserSecretRef))
+		}
+		if mail.Options.Auth.PassSecretRef != nil {
+			env = append(env, secretEnvVar("mail__options__auth__pass", mail.Options.Auth.PassSecretRef))
+		}
+	}
+
+	return env
+}
+
+func secretEnvVar(name string, ref *corev1.SecretKeySelector) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: ref.DeepCopy(),
+		},
+	}
+}
+
+// secretsHashForCR resolves every secret referenced by cr and returns a hash of the referenced values.
+// The hash changes whenever a referenced value is rotated.
+func (r *ReconcileGhostApp) secretsHashForCR(cr *ghostv1alpha1.GhostApp) (string, error) {
+	hash := sha256.New()
+	for _, env := range secretEnvFromCR(cr) {
+		ref := env.ValueFrom.SecretKeyRef
+		optional := ref.Optional != nil && *ref.Optional
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Name: ref.Name, Namespace: cr.GetNamespace()}
+		if err := r.client.Get(context.TODO(), key, secret); err != nil {
+			if errors.IsNotFound(err) && optional {
+				continue
+			}
+			return "", fmt.Errorf("failed to get secret %s referenced by %s: %v", ref.Name, env.Name, err)
+		}
+
+		value, ok := secret.Data[ref.Key]
+		if !ok && !optional {
+			return "", fmt.Errorf("secret %s referenced by %s has no key %s", ref.Name, env.Name, ref.Key)
+		}
+
+		fmt.Fprintf(hash, "%s=%s/%s:", env.Name, ref.Name, ref.Key)
+		hash.Write(value)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// secretToGhostApps maps a secret to reconcile requests of all GhostApp referencing it.
+type secretToGhostApps struct {
+	client client.Client
+}
+
+func (m *secretToGhostApps) Map(obj handler.MapObject) []reconcile.Request {
+	list := &ghostv1alpha1.GhostAppList{}
+	if err := m.client.List(context.TODO(), list, client.InNamespace(obj.Meta.GetNamespace())); err != nil {
+		log.Error(err, "Failed to list GhostApp referencing secret", "Secret.Name", obj.Meta.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range list.Items {
+		cr := &list.Items[i]
+		for _, env := range secretEnvFromCR(cr) {
+			if env.ValueFrom.SecretKeyRef.Name == obj.Meta.GetName() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()},
+				})
+				break
+			}
+		}
+	}
+
+	return requests
+}
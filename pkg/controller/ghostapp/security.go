@@ -0,0 +1,92 @@
+// Copyright 2020 Fossil Dev
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ghostapp
+
+import (
+	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// seccompProfileRuntimeDefault is the default seccomp profile of the container runtime.
+const seccompProfileRuntimeDefault = "RuntimeDefault"
+
+// podSecurityContextForCR returns security context of ghost pods, running ghost as the node user of the official
+// ghost image by default, so ghost content volume is writable by ghost.
+func podSecurityContextForCR(cr *ghostv1alpha1.GhostApp) *corev1.PodSecurityContext {
+	runAsNonRoot := true
+	return &corev1.PodSecurityContext{
+		RunAsUser:    cr.Spec.Security.RunAsUser,
+		RunAsGroup:   cr.Spec.Security.RunAsGroup,
+		FSGroup:      cr.Spec.Security.FSGroup,
+		RunAsNonRoot: &runAsNonRoot,
+	}
+}
+
+// withRuntimeDefaultSeccompProfile converts dep to an unstructured object whose pods run with the runtime default
+// seccomp profile. PodSecurityContext of the Kubernetes API version used by this operator has no seccompProfile field
+// yet, so the field is only set in the applied object.
+func withRuntimeDefaultSeccompProfile(dep *appsv1.Deployment) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(dep)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+	err = unstructured.SetNestedField(u.Object, seccompProfileRuntimeDefault,
+		"spec", "template", "spec", "securityContext", "seccompProfile", "type")
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// containerSecurityContextForCR returns security context of ghost container compliant with
+// the restricted Pod Security Standard.
+func containerSecurityContextForCR(cr *ghostv1alpha1.GhostApp) *corev1.SecurityContext {
+	readOnlyRootFilesystem := cr.Spec.Security.ReadOnlyRootFilesystem
+	security := restrictedSecurityContext()
+	security.ReadOnlyRootFilesystem = &readOnlyRootFilesystem
+	return security
+}
+
+// restrictedSecurityContext returns container security context required by the restricted Pod Security Standard.
+func restrictedSecurityContext() *corev1.SecurityContext {
+	allowPrivilegeEscalation := false
+	return &corev1.SecurityContext{
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+		AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+	}
+}
+
+// restrictContainers returns copies of user supplied containers, setting the restricted security context on
+// containers without their own security context.
+func restrictContainers(containers []corev1.Container) []corev1.Container {
+	if containers == nil {
+		return nil
+	}
+	restricted := make([]corev1.Container, len(containers))
+	for i := range containers {
+		containers[i].DeepCopyInto(&restricted[i])
+		if restricted[i].SecurityContext == nil {
+			restricted[i].SecurityContext = restrictedSecurityContext()
+		}
+	}
+	return restricted
+}
@@ -15,58 +15,40 @@
 package ghostapp
 
 import (
-	"context"
-
 	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 func (r *ReconcileGhostApp) CreateOrUpdateService(cr *ghostv1alpha1.GhostApp) error {
-	serviceType := corev1.ServiceTypeClusterIP
-	// set serviceType to NodePort when ingress enabled
-	// we need this type for some ingress-class eg: gce
-	// default is ClusterIP
-	// TODO (prksu): Consider adding ServiceType field in GhostAppSpec
-	if cr.Spec.Ingress.Enabled {
-		serviceType = corev1.ServiceTypeNodePort
-	}
-
+	spec := cr.Spec.Service
 	svc := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      cr.GetName(),
-			Namespace: cr.GetNamespace(),
-			Labels:    commonLabelFromCR(cr),
+			Name:        cr.GetName(),
+			Namespace:   cr.GetNamespace(),
+			Labels:      mergeStringMap(spec.Labels, commonLabelFromCR(cr)),
+			Annotations: spec.Annotations,
 		},
-	}
-
-	op, err := controllerutil.CreateOrUpdate(context.TODO(), r.client, svc, func() error {
-		// We don't accept any update for service
-		if !svc.ObjectMeta.CreationTimestamp.IsZero() {
-			return nil
-		}
-
-		if err := controllerutil.SetControllerReference(cr, svc, r.scheme); err != nil {
-			return err
-		}
-
-		svc.Spec = corev1.ServiceSpec{
+		Spec: corev1.ServiceSpec{
 			Selector: commonLabelFromCR(cr),
-			Type:     serviceType,
+			Type:     cr.ServiceType(),
 			Ports: []corev1.ServicePort{
 				{
 					Name:       "http",
 					Protocol:   "TCP",
-					Port:       int32(2368),
-					TargetPort: intstr.FromInt(int(2368)),
+					Port:       spec.Port,
+					TargetPort: intstr.FromString("http"),
+					NodePort:   spec.NodePort,
 				},
 			},
-		}
-		return nil
-	})
+			LoadBalancerSourceRanges: spec.LoadBalancerSourceRanges,
+			ExternalTrafficPolicy:    spec.ExternalTrafficPolicy,
+		},
+	}
 
+	op, err := r.apply(cr, svc)
 	r.logger.Info("Reconciling Service", "Operation.Result", op)
+	r.recordOperation(cr, "Service", svc.GetName(), op)
 	return err
 }
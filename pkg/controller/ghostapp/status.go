@@ -0,0 +1,318 @@
+// Copyright 2020 Fossil Dev
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ghostapp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	ghostv1alpha1 "fossil.or.id/ghost-operator/pkg/apis/ghost/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// rolloutRequeueInterval is the interval to requeue GhostApp while its deployment rollout is in progress.
+const rolloutRequeueInterval = 10 * time.Second
+
+// updateConditions sets conditions of cr status based on the observed state of its child objects.
+func (r *ReconcileGhostApp) updateConditions(cr *ghostv1alpha1.GhostApp, dep *appsv1.Deployment) error {
+	cr.SetCondition(ghostv1alpha1.GhostAppConditionConfigReady, corev1.ConditionTrue, "ConfigRendered",
+		fmt.Sprintf("Ghost configuration is rendered into secret %s", configSecretNameFromCR(cr)))
+
+	if err := r.updateStorageCondition(cr); err != nil {
+		return err
+	}
+
+	updateDeploymentCondition(cr, dep)
+
+	if err := r.updateServiceCondition(cr); err != nil {
+		return err
+	}
+
+	if err := r.updateIngressCondition(cr); err != nil {
+		return err
+	}
+
+	if err := r.updateAdminIngressCondition(cr); err != nil {
+		return err
+	}
+
+	r.updateURLCondition(cr)
+
+	if err := r.updateHTTPRouteCondition(cr); err != nil {
+		return err
+	}
+
+	if err := r.updateRouteCondition(cr); err != nil {
+		return err
+	}
+
+	var notReady []string
+	for _, conditionType := range []ghostv1alpha1.GhostAppConditionType{
+		ghostv1alpha1.GhostAppConditionConfigReady,
+		ghostv1alpha1.GhostAppConditionStorageReady,
+		ghostv1alpha1.GhostAppConditionDeploymentAvailable,
+		ghostv1alpha1.GhostAppConditionServiceReady,
+		ghostv1alpha1.GhostAppConditionIngressReady,
+		ghostv1alpha1.GhostAppConditionAdminIngressReady,
+		ghostv1alpha1.GhostAppConditionHTTPRouteAccepted,
+		ghostv1alpha1.GhostAppConditionRouteAdmitted,
+	} {
+		if !cr.IsConditionTrue(conditionType) {
+			notReady = append(notReady, string(conditionType))
+		}
+	}
+
+	if len(notReady) > 0 {
+		cr.SetCondition(ghostv1alpha1.GhostAppConditionReady, corev1.ConditionFalse, "ComponentsNotReady",
+			fmt.Sprintf("Waiting for %s", strings.Join(notReady, ", ")))
+	} else {
+		cr.SetCondition(ghostv1alpha1.GhostAppConditionReady, corev1.ConditionTrue, "Ready", "GhostApp is ready")
+	}
+
+	return nil
+}
+
+func (r *ReconcileGhostApp) updateStorageCondition(cr *ghostv1alpha1.GhostApp) error {
+	pvc := &corev1.PersistentVolumeClaim{}
+	key := types.NamespacedName{Name: persistentVolumeClaimNameFromCR(cr), Namespace: cr.GetNamespace()}
+	if !cr.IsPersistentEnabled() {
+		message := "Ghost content is stored in an emptyDir volume"
+		if err := r.client.Get(context.TODO(), key, pvc); err == nil {
+			if pvc.GetLabels()[retainedFromLabel] == cr.GetName() {
+				message += fmt.Sprintf(", previous PersistentVolumeClaim %s is retained", pvc.GetName())
+			} else if pvc.GetDeletionTimestamp() != nil {
+				message += fmt.Sprintf(", previous PersistentVolumeClaim %s is being deleted", pvc.GetName())
+			}
+		} else if !errors.IsNotFound(err) {
+			return err
+		}
+
+		cr.SetCondition(ghostv1alpha1.GhostAppConditionStorageReady, corev1.ConditionTrue, "EmptyDir", message)
+		return nil
+	}
+
+	// PersistentVolumeClaim created by this reconcile may not be observed by the cache yet.
+	if err := r.client.Get(context.TODO(), key, pvc); errors.IsNotFound(err) {
+		cr.SetCondition(ghostv1alpha1.GhostAppConditionStorageReady, corev1.ConditionFalse, "PersistentVolumeClaimPending",
+			fmt.Sprintf("PersistentVolumeClaim %s is not created yet", key.Name))
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if pvc.Status.Phase == corev1.ClaimBound {
+		cr.SetCondition(ghostv1alpha1.GhostAppConditionStorageReady, corev1.ConditionTrue, "PersistentVolumeClaimBound",
+			fmt.Sprintf("PersistentVolumeClaim %s is bound", pvc.GetName()))
+	} else {
+		cr.SetCondition(ghostv1alpha1.GhostAppConditionStorageReady, corev1.ConditionFalse, "PersistentVolumeClaimNotBound",
+			fmt.Sprintf("PersistentVolumeClaim %s is not bound yet", pvc.GetName()))
+	}
+
+	return nil
+}
+
+// updateDeploymentCondition sets DeploymentAvailable condition of cr from dep, nil when dep is not observed yet.
+func updateDeploymentCondition(cr *ghostv1alpha1.GhostApp, dep *appsv1.Deployment) {
+	if dep != nil {
+		if condition := deploymentCondition(dep, appsv1.DeploymentAvailable); condition != nil {
+			cr.SetCondition(ghostv1alpha1.GhostAppConditionDeploymentAvailable, condition.Status, condition.Reason, condition.Message)
+			return
+		}
+	}
+
+	cr.SetCondition(ghostv1alpha1.GhostAppConditionDeploymentAvailable, corev1.ConditionUnknown, "DeploymentPending",
+		fmt.Sprintf("Deployment %s has not reported its availability yet", cr.GetName()))
+}
+
+// updateRolloutStatus sets status phase and replicas of cr from the rollout status of dep,
+// nil when dep is not observed yet. It returns false while the rollout is still in progress.
+func updateRolloutStatus(cr *ghostv1alpha1.GhostApp, dep *appsv1.Deployment) bool {
+	cr.Status.Replicas = 0
+	cr.Status.ReadyReplicas = 0
+	cr.Status.Reason = ""
+	if dep != nil {
+		cr.Status.Replicas = dep.Status.Replicas
+		cr.Status.ReadyReplicas = dep.Status.ReadyReplicas
+
+		if condition := deploymentCondition(dep, appsv1.DeploymentProgressing); condition != nil && condition.Reason == "ProgressDeadlineExceeded" {
+			cr.Status.Phase = ghostv1alpha1.GhostAppPhaseFailure
+			cr.Status.Reason = condition.Message
+			return true
+		}
+
+		if isRolloutComplete(dep) {
+			cr.Status.Phase = ghostv1alpha1.GhostAppPhaseRunning
+			return true
+		}
+	}
+
+	// GhostApp that has never been running is still being created, any later rollout is an update.
+	if cr.Status.Phase == "" || cr.Status.Phase == ghostv1alpha1.GhostAppPhaseCreating {
+		cr.Status.Phase = ghostv1alpha1.GhostAppPhaseCreating
+	} else {
+		cr.Status.Phase = ghostv1alpha1.GhostAppPhaseUpdating
+	}
+
+	return false
+}
+
+// isRolloutComplete returns true when all replicas of dep run the latest pod template and are available,
+// the same way "kubectl rollout status" does.
+func isRolloutComplete(dep *appsv1.Deployment) bool {
+	if dep.GetGeneration() > dep.Status.ObservedGeneration {
+		return false
+	}
+
+	if dep.Spec.Replicas != nil && dep.Status.UpdatedReplicas < *dep.Spec.Replicas {
+		return false
+	}
+
+	// Old replicas are still being terminated
+	if dep.Status.Replicas > dep.Status.UpdatedReplicas {
+		return false
+	}
+
+	return dep.Status.AvailableReplicas >= dep.Status.UpdatedReplicas
+}
+
+func deploymentCondition(dep *appsv1.Deployment, conditionType appsv1.DeploymentConditionType) *appsv1.DeploymentCondition {
+	for i := range dep.Status.Conditions {
+		if dep.Status.Conditions[i].Type == conditionType {
+			return &dep.Status.Conditions[i]
+		}
+	}
+
+	return nil
+}
+
+func (r *ReconcileGhostApp) updateServiceCondition(cr *ghostv1alpha1.GhostApp) error {
+	endpoints := &corev1.Endpoints{}
+	key := types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}
+	if err := r.client.Get(context.TODO(), key, endpoints); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			cr.SetCondition(ghostv1alpha1.GhostAppConditionServiceReady, corev1.ConditionTrue, "EndpointsReady",
+				fmt.Sprintf("Service %s has ready endpoints", cr.GetName()))
+			return nil
+		}
+	}
+
+	cr.SetCondition(ghostv1alpha1.GhostAppConditionServiceReady, corev1.ConditionFalse, "NoReadyEndpoints",
+		fmt.Sprintf("Service %s has no ready endpoints", cr.GetName()))
+	return nil
+}
+
+func (r *ReconcileGhostApp) updateIngressCondition(cr *ghostv1alpha1.GhostApp) error {
+	if !cr.IsIngressEnabled() {
+		cr.Status.Certificate = nil
+		cr.SetCondition(ghostv1alpha1.GhostAppConditionIngressReady, corev1.ConditionTrue, "IngressDisabled",
+			"Ingress is not enabled")
+		return nil
+	}
+
+	if isCertificateManaged(cr) {
+		if ready, err := r.updateCertificateStatus(cr); err != nil || !ready {
+			return err
+		}
+	} else {
+		cr.Status.Certificate = nil
+	}
+
+	return r.updateIngressAddressCondition(cr, ghostv1alpha1.GhostAppConditionIngressReady, cr.GetName())
+}
+
+func (r *ReconcileGhostApp) updateAdminIngressCondition(cr *ghostv1alpha1.GhostApp) error {
+	if !cr.IsAdminEnabled() {
+		cr.SetCondition(ghostv1alpha1.GhostAppConditionAdminIngressReady, corev1.ConditionTrue, "AdminDisabled",
+			"Admin ingress is not enabled")
+		return nil
+	}
+
+	return r.updateIngressAddressCondition(cr, ghostv1alpha1.GhostAppConditionAdminIngressReady, adminIngressNameFromCR(cr))
+}
+
+// updateIngressAddressCondition sets condition of cr to true once ingress with the given name has an address.
+func (r *ReconcileGhostApp) updateIngressAddressCondition(cr *ghostv1alpha1.GhostApp, conditionType ghostv1alpha1.GhostAppConditionType, name string) error {
+	ing := &unstructured.Unstructured{}
+	ing.SetGroupVersionKind(r.ingressKind())
+	key := types.NamespacedName{Name: name, Namespace: cr.GetNamespace()}
+	if err := r.client.Get(context.TODO(), key, ing); err != nil {
+		return err
+	}
+
+	if addresses, _, _ := unstructured.NestedSlice(ing.Object, "status", "loadBalancer", "ingress"); len(addresses) > 0 {
+		cr.SetCondition(conditionType, corev1.ConditionTrue, "IngressAdmitted",
+			fmt.Sprintf("Ingress %s has an address", ing.GetName()))
+	} else {
+		cr.SetCondition(conditionType, corev1.ConditionFalse, "AddressPending",
+			fmt.Sprintf("Ingress %s has no address yet", ing.GetName()))
+	}
+
+	return nil
+}
+
+// updateURLCondition reports whether config.url matches scheme, host and path of ghost ingress. Ghost generates
+// links and redirects from config.url, so a mismatch breaks the site even though every child object is ready.
+func (r *ReconcileGhostApp) updateURLCondition(cr *ghostv1alpha1.GhostApp) {
+	if !cr.IsIngressEnabled() {
+		cr.SetCondition(ghostv1alpha1.GhostAppConditionURLConsistent, corev1.ConditionTrue, "IngressDisabled",
+			"Ingress is not enabled")
+		return
+	}
+
+	rawurl := cr.Spec.Config.URL
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		// config.url is validated before reconciling child objects
+		return
+	}
+
+	hosts := cr.IngressHosts()
+	path := cr.IngressPath()
+	switch {
+	case u.Scheme == "https" && !cr.Spec.Ingress.TLS.Enabled:
+		r.setWarningCondition(cr, ghostv1alpha1.GhostAppConditionURLConsistent, "TLSNotEnabled",
+			fmt.Sprintf("config.url %s is https but ingress tls is not enabled", rawurl))
+	case u.Scheme == "http" && cr.Spec.Ingress.TLS.Enabled:
+		r.setWarningCondition(cr, ghostv1alpha1.GhostAppConditionURLConsistent, "InsecureURL",
+			fmt.Sprintf("ingress tls is enabled but config.url %s is http, ghost generates insecure links", rawurl))
+	case len(hosts) > 0 && !containsString(hosts, u.Hostname()):
+		r.setWarningCondition(cr, ghostv1alpha1.GhostAppConditionURLConsistent, "HostMismatch",
+			fmt.Sprintf("host of config.url %s is not one of ingress hosts %s", rawurl, strings.Join(hosts, ", ")))
+	case !isSubpath(u.Path, path):
+		r.setWarningCondition(cr, ghostv1alpha1.GhostAppConditionURLConsistent, "PathMismatch",
+			fmt.Sprintf("path of config.url %s is not routed by ingress path %s", rawurl, path))
+	default:
+		cr.SetCondition(ghostv1alpha1.GhostAppConditionURLConsistent, corev1.ConditionTrue, "URLMatched",
+			fmt.Sprintf("config.url %s is served by ingress", rawurl))
+	}
+}
+
+// isSubpath returns true when prefix is path or one of its parent directories.
+func isSubpath(path, prefix string) bool {
+	path = strings.TrimSuffix(path, "/")
+	prefix = strings.TrimSuffix(prefix, "/")
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}